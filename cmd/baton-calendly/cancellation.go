@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func newCancelMeetingsCommand(ctx context.Context, v *viper.Viper) *cobra.Command {
+	var (
+		email      string
+		reportFile string
+		confirm    bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "cancel-meetings",
+		Short: "Cancel upcoming meetings hosted by a departing organization member",
+		Long: "Cancels every active meeting starting in the future hosted by the member, sending invitees the configured " +
+			"cancellation reason. By default only a report is written, meetings are canceled only with --confirm.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			runCtx, err := initCommand(ctx, v)
+			if err != nil {
+				return err
+			}
+
+			cb, err := newCalendly(runCtx, v)
+			if err != nil {
+				return err
+			}
+
+			results, err := cb.CancelUpcomingMeetings(runCtx, email, confirm)
+			if err != nil {
+				return err
+			}
+
+			out, err := openOutput(reportFile)
+			if err != nil {
+				return err
+			}
+			defer out.Close()
+
+			err = writeJSON(out, results)
+			if err != nil {
+				return err
+			}
+
+			for _, r := range results {
+				if r.Error != "" {
+					return fmt.Errorf("failed to cancel some meetings, see the report")
+				}
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&email, "email", "", "Email of the departing member")
+	cmd.Flags().StringVar(&reportFile, "report-file", "", "Path of the JSON report file. The report is written to stdout when empty")
+	cmd.Flags().BoolVar(&confirm, "confirm", false, "Cancel the meetings instead of only reporting them")
+	_ = cmd.MarkFlagRequired("email")
+
+	return cmd
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/conductorone/baton-calendly/pkg/calendly"
+	"github.com/conductorone/baton-calendly/pkg/connector"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const auditFileName = "calendly-data-compliance.jsonl"
+
+func newDeleteInviteeDataCommand(ctx context.Context, v *viper.Viper) *cobra.Command {
+	var auditFile string
+
+	cmd := &cobra.Command{
+		Use:   "delete-invitee-data [email]...",
+		Short: "Request deletion of all Calendly invitee data for the given emails",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			runCtx, err := initCommand(ctx, v)
+			if err != nil {
+				return err
+			}
+
+			cb, err := newCalendly(runCtx, v)
+			if err != nil {
+				return err
+			}
+
+			records, err := cb.DeleteInviteeData(runCtx, args)
+			if err != nil {
+				return err
+			}
+
+			err = appendAuditRecords(auditPath(v, auditFile), records)
+			if err != nil {
+				return err
+			}
+
+			for _, r := range records {
+				if r.Status != connector.DataComplianceStatusAccepted {
+					return fmt.Errorf("invitee data deletion failed: %s", r.Error)
+				}
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&auditFile, "audit-file", "", "Path of the JSON lines audit file. Defaults to "+auditFileName+" next to the sync file")
+
+	return cmd
+}
+
+// auditPath returns the audit file path, by default placed alongside the c1z sync output.
+func auditPath(v *viper.Viper, path string) string {
+	if path != "" {
+		return path
+	}
+
+	return filepath.Join(filepath.Dir(v.GetString("file")), auditFileName)
+}
+
+// appendAuditRecords appends the records to the audit file as JSON lines.
+func appendAuditRecords(path string, records []*connector.DataComplianceRecord) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit file: %w", err)
+	}
+	defer f.Close()
+
+	enc := json.NewEncoder(f)
+	for _, r := range records {
+		if err := enc.Encode(r); err != nil {
+			return fmt.Errorf("failed to write audit record: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func newDeleteEventDataCommand(ctx context.Context, v *viper.Viper) *cobra.Command {
+	var (
+		startTime     string
+		endTime       string
+		olderThanDays int
+		confirm       bool
+		auditFile     string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "delete-event-data",
+		Short: "Request deletion of Calendly scheduled event data within a time window",
+		Long: "Requests deletion of scheduled event data within a time window of at most 24 months. " +
+			"By default only the number of affected scheduled events is reported, data is deleted only with --confirm.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			end, err := parseTimeFlag(endTime)
+			if err != nil {
+				return fmt.Errorf("invalid end time: %w", err)
+			}
+
+			if olderThanDays > 0 {
+				if !end.IsZero() {
+					return fmt.Errorf("end time and older than days can't be used together")
+				}
+				end = time.Now().Add(-days(olderThanDays))
+			}
+
+			if end.IsZero() {
+				return fmt.Errorf("end time or older than days is required")
+			}
+
+			start, err := parseTimeFlag(startTime)
+			if err != nil {
+				return fmt.Errorf("invalid start time: %w", err)
+			}
+
+			if start.IsZero() {
+				start = end.Add(-calendly.MaxEventDataDeletionRange)
+			}
+
+			runCtx, err := initCommand(ctx, v)
+			if err != nil {
+				return err
+			}
+
+			cb, err := newCalendly(runCtx, v)
+			if err != nil {
+				return err
+			}
+
+			record, err := cb.DeleteEventData(runCtx, start, end, !confirm)
+			if err != nil {
+				return err
+			}
+
+			err = appendAuditRecords(auditPath(v, auditFile), []*connector.DataComplianceRecord{record})
+			if err != nil {
+				return err
+			}
+
+			if record.Status == connector.DataComplianceStatusFailed {
+				return fmt.Errorf("scheduled event data deletion failed: %s", record.Error)
+			}
+
+			return writeJSON(cmd.OutOrStdout(), record)
+		},
+	}
+
+	cmd.Flags().StringVar(&startTime, "start-time", "", "Start of the time window, RFC 3339 time or YYYY-MM-DD date. Defaults to 24 months before the end")
+	cmd.Flags().StringVar(&endTime, "end-time", "", "End of the time window, RFC 3339 time or YYYY-MM-DD date")
+	cmd.Flags().IntVar(&olderThanDays, "older-than-days", 0, "Set the end of the time window to the given number of days ago")
+	cmd.Flags().BoolVar(&confirm, "confirm", false, "Delete the data instead of only counting the affected scheduled events")
+	cmd.Flags().StringVar(&auditFile, "audit-file", "", "Path of the JSON lines audit file. Defaults to "+auditFileName+" next to the sync file")
+
+	return cmd
+}
+
+func parseTimeFlag(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, nil
+	}
+
+	return time.Parse(time.RFC3339, value)
+}
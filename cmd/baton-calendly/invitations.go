@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/conductorone/baton-calendly/pkg/connector"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func newStaleInvitationsCommand(ctx context.Context, v *viper.Viper) *cobra.Command {
+	var (
+		olderThanDays int
+		action        string
+		reportFile    string
+		confirm       bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "stale-invitations",
+		Short: "Report, resend or expire pending invitations older than a threshold",
+		Long: "Finds pending invitations older than the threshold. With the resend action they are canceled and sent again, " +
+			"with the expire action they are canceled. By default only a report is written, invitations change only with --confirm.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !slices.Contains(connector.StaleInvitationActions, action) {
+				return fmt.Errorf("unsupported action: %s", action)
+			}
+
+			runCtx, err := initCommand(ctx, v)
+			if err != nil {
+				return err
+			}
+
+			cb, err := newCalendly(runCtx, v)
+			if err != nil {
+				return err
+			}
+
+			// opened first, so invitations aren't changed when the report can't be written
+			out, err := openOutput(reportFile)
+			if err != nil {
+				return err
+			}
+			defer out.Close()
+
+			results, err := cb.ProcessStaleInvitations(runCtx, &connector.StaleInvitationOptions{
+				OlderThan: days(olderThanDays),
+				Action:    action,
+				Confirm:   confirm,
+			})
+			if err != nil {
+				return err
+			}
+
+			err = writeJSON(out, results)
+			if err != nil {
+				return err
+			}
+
+			printStaleInvitationsSummary(cmd, action, confirm, results)
+
+			for _, r := range results {
+				if r.Error != "" {
+					return fmt.Errorf("failed to process some invitations, see the report")
+				}
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&olderThanDays, "older-than-days", 30, "Age in days after which a pending invitation is stale")
+	cmd.Flags().StringVar(&action, "action", connector.StaleInvitationReport, "Action taken on stale invitations: "+strings.Join(connector.StaleInvitationActions, ", "))
+	cmd.Flags().StringVar(&reportFile, "report-file", "", "Path of the JSON report file. The report is written to stdout when empty")
+	cmd.Flags().BoolVar(&confirm, "confirm", false, "Perform the action instead of only reporting the invitations")
+
+	return cmd
+}
+
+// printStaleInvitationsSummary writes the affected emails to stderr, so it doesn't mix with the report on stdout.
+func printStaleInvitationsSummary(cmd *cobra.Command, action string, confirm bool, results []*connector.StaleInvitationResult) {
+	var emails []string
+	for _, r := range results {
+		if !slices.Contains(emails, r.Email) {
+			emails = append(emails, r.Email)
+		}
+	}
+
+	mode := "dry run"
+	if confirm && action != connector.StaleInvitationReport {
+		mode = "applied"
+	}
+
+	w := cmd.ErrOrStderr()
+	fmt.Fprintf(w, "%d stale invitations of %d emails, action %s (%s)\n", len(results), len(emails), action, mode)
+	for _, email := range emails {
+		fmt.Fprintf(w, "  %s\n", email)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/conductorone/baton-calendly/pkg/connector"
 	configSchema "github.com/conductorone/baton-sdk/pkg/config"
@@ -19,16 +20,73 @@ const (
 	version       = "dev"
 	connectorName = "baton-calendly"
 	token         = "token"
+
+	activityLookbackDays   = "activity-lookback-days"
+	includeEmailDomains    = "include-email-domains"
+	excludeEmailDomains    = "exclude-email-domains"
+	roles                  = "roles"
+	skipPendingInvitations = "skip-pending-invitations"
+	invitationHistory      = "invitation-history"
+	pageSize               = "page-size"
+	adaptivePageSize       = "adaptive-page-size"
+	lookupCacheTTL         = "lookup-cache-ttl"
+	incrementalSync        = "incremental-sync"
+	fullSyncIntervalDays   = "full-sync-interval-days"
+	scimBaseURL            = "scim-base-url"
+	scimToken              = "scim-token"
+	forceOffboarding       = "force-offboarding"
+	cancelMeetings         = "cancel-meetings-on-offboarding"
+	cancellationReason     = "cancellation-reason"
+	webhookEventsFile      = "webhook-events-file"
+	webhookSigningKey      = "webhook-signing-key"
 )
 
 var (
-	tokenField          = field.StringField(token, field.WithRequired(true), field.WithDescription("Personal Access Token used to authenticate with the Calendly API."))
-	configurationFields = []field.SchemaField{tokenField}
+	tokenField                  = field.StringField(token, field.WithRequired(true), field.WithDescription("Personal Access Token used to authenticate with the Calendly API."))
+	activityLookbackField       = field.IntField(activityLookbackDays, field.WithDescription("Number of days to look back in scheduled events to find the last activity of each user. Disabled when 0."))
+	includeEmailDomainsField    = field.StringSliceField(includeEmailDomains, field.WithDescription("Sync only users with email in one of the domains."))
+	excludeEmailDomainsField    = field.StringSliceField(excludeEmailDomains, field.WithDescription("Skip users with email in one of the domains."))
+	rolesField                  = field.StringSliceField(roles, field.WithDescription("Sync only organization members with one of the roles: user, admin, owner."))
+	skipPendingInvitationsField = field.BoolField(skipPendingInvitations, field.WithDescription("Skip users with pending invitation to the organization."))
+	invitationHistoryField      = field.BoolField(invitationHistory, field.WithDescription("Record accepted and declined invitations of members on their organization role grants."))
+	pageSizeField               = field.IntField(pageSize, field.WithDescription("Number of items requested per page, at most 100."), field.WithDefaultValue(connector.ResourcesPageSize))
+	adaptivePageSizeField       = field.BoolField(adaptivePageSize, field.WithDescription("Grow the page size up to 100 while the rate limit has headroom, shrink it back and slow down requests as it runs out."))
+	lookupCacheTTLField         = field.IntField(lookupCacheTTL, field.WithDescription("Seconds to cache lookups of the current user and organization. Disabled when 0."), field.WithDefaultValue(300))
+	incrementalSyncField        = field.BoolField(incrementalSync, field.WithDescription("Emit only user role grants changed since the last full sync, reusing the rest from the previous sync."))
+	fullSyncIntervalField       = field.IntField(fullSyncIntervalDays, field.WithDescription("Number of days after which incremental sync emits all grants again, even when no member changed."), field.WithDefaultValue(7))
+	scimBaseURLField            = field.StringField(scimBaseURL, field.WithDescription("Base URL of the Calendly SCIM API. Enables SCIM provisioning together with the SCIM token."))
+	scimTokenField              = field.StringField(scimToken, field.WithDescription("Token used to authenticate with the Calendly SCIM API."))
+	forceOffboardingField       = field.BoolField(forceOffboarding, field.WithDescription("Remove members even when they host upcoming meetings or own event types."))
+	cancelMeetingsField         = field.BoolField(cancelMeetings, field.WithDescription("Cancel upcoming meetings hosted by a member before the member is removed."))
+	cancellationReasonField     = field.StringField(cancellationReason, field.WithDescription("Template of the reason sent to invitees of canceled meetings, with {{.HostName}}, {{.HostEmail}}, {{.EventName}} and {{.StartTime}} fields."), field.WithDefaultValue(connector.DefaultCancellationReason))
+	webhookEventsFileField      = field.StringField(webhookEventsFile, field.WithDescription("JSON lines file where received webhooks are buffered and served as usage events."))
+	webhookSigningKeyField      = field.StringField(webhookSigningKey, field.WithDescription("Signing key of the Calendly webhook subscription used to verify received webhooks."))
+	configurationFields         = []field.SchemaField{
+		tokenField,
+		activityLookbackField,
+		includeEmailDomainsField,
+		excludeEmailDomainsField,
+		rolesField,
+		skipPendingInvitationsField,
+		invitationHistoryField,
+		pageSizeField,
+		adaptivePageSizeField,
+		lookupCacheTTLField,
+		incrementalSyncField,
+		fullSyncIntervalField,
+		scimBaseURLField,
+		scimTokenField,
+		forceOffboardingField,
+		cancelMeetingsField,
+		cancellationReasonField,
+		webhookEventsFileField,
+		webhookSigningKeyField,
+	}
 )
 
 func main() {
 	ctx := context.Background()
-	_, cmd, err := configSchema.DefineConfiguration(ctx,
+	v, cmd, err := configSchema.DefineConfiguration(ctx,
 		connectorName,
 		getConnector,
 		field.NewConfiguration(configurationFields),
@@ -39,6 +97,17 @@ func main() {
 	}
 
 	cmd.Version = version
+	cmd.AddCommand(
+		newReclaimCommand(ctx, v),
+		newDeleteInviteeDataCommand(ctx, v),
+		newDeleteEventDataCommand(ctx, v),
+		newCreateSchedulingLinkCommand(ctx, v),
+		newServeWebhooksCommand(ctx, v),
+		newCancelMeetingsCommand(ctx, v),
+		newStaleInvitationsCommand(ctx, v),
+		newReconcileCommand(ctx, v),
+	)
+
 	err = cmd.Execute()
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err.Error())
@@ -48,7 +117,7 @@ func main() {
 
 func getConnector(ctx context.Context, cfg *viper.Viper) (types.ConnectorServer, error) {
 	l := ctxzap.Extract(ctx)
-	cb, err := connector.New(ctx, cfg.GetString(token))
+	cb, err := newCalendly(ctx, cfg)
 	if err != nil {
 		l.Error("error creating connector", zap.Error(err))
 		return nil, err
@@ -62,3 +131,29 @@ func getConnector(ctx context.Context, cfg *viper.Viper) (types.ConnectorServer,
 
 	return c, nil
 }
+
+func newCalendly(ctx context.Context, cfg *viper.Viper) (*connector.Calendly, error) {
+	return connector.New(ctx, cfg.GetString(token), &connector.Config{
+		ActivityLookback:            days(cfg.GetInt(activityLookbackDays)),
+		IncludeEmailDomains:         cfg.GetStringSlice(includeEmailDomains),
+		ExcludeEmailDomains:         cfg.GetStringSlice(excludeEmailDomains),
+		Roles:                       cfg.GetStringSlice(roles),
+		SkipPendingInvitations:      cfg.GetBool(skipPendingInvitations),
+		InvitationHistory:           cfg.GetBool(invitationHistory),
+		PageSize:                    cfg.GetInt(pageSize),
+		AdaptivePageSize:            cfg.GetBool(adaptivePageSize),
+		LookupCacheTTL:              time.Duration(cfg.GetInt(lookupCacheTTL)) * time.Second,
+		IncrementalSync:             cfg.GetBool(incrementalSync),
+		FullSyncInterval:            days(cfg.GetInt(fullSyncIntervalDays)),
+		SCIMBaseURL:                 cfg.GetString(scimBaseURL),
+		SCIMToken:                   cfg.GetString(scimToken),
+		ForceOffboarding:            cfg.GetBool(forceOffboarding),
+		CancelMeetingsOnOffboarding: cfg.GetBool(cancelMeetings),
+		CancellationReason:          cfg.GetString(cancellationReason),
+		WebhookEventsFile:           cfg.GetString(webhookEventsFile),
+	})
+}
+
+func days(n int) time.Duration {
+	return time.Duration(n) * 24 * time.Hour
+}
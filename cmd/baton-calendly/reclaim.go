@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/conductorone/baton-calendly/pkg/connector"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	reportFormatJSON = "json"
+	reportFormatCSV  = "csv"
+)
+
+func newReclaimCommand(ctx context.Context, v *viper.Viper) *cobra.Command {
+	var (
+		inactiveDays int
+		allowList    []string
+		reportFile   string
+		reportFormat string
+		confirm      bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "reclaim",
+		Short: "Report and remove organization members without scheduled events in the inactivity period",
+		Long: "Finds organization members, other than owners, the token owner and allow-listed ones, who haven't hosted any scheduled event " +
+			"in the inactivity period. By default only a report is written, members are removed only with --confirm.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if reportFormat != reportFormatJSON && reportFormat != reportFormatCSV {
+				return fmt.Errorf("unsupported report format: %s", reportFormat)
+			}
+
+			runCtx, err := initCommand(ctx, v)
+			if err != nil {
+				return err
+			}
+
+			cb, err := newCalendly(runCtx, v)
+			if err != nil {
+				return err
+			}
+
+			// opened first, so members aren't removed when the report can't be written
+			out, err := openOutput(reportFile)
+			if err != nil {
+				return err
+			}
+			defer out.Close()
+
+			results, err := cb.ReclaimInactiveSeats(runCtx, &connector.ReclaimOptions{
+				InactiveFor: days(inactiveDays),
+				AllowList:   allowList,
+				Confirm:     confirm,
+			})
+			if err != nil {
+				return err
+			}
+
+			if reportFormat == reportFormatCSV {
+				return writeReclaimCSV(out, results)
+			}
+
+			return writeJSON(out, results)
+		},
+	}
+
+	cmd.Flags().IntVar(&inactiveDays, "inactive-days", 90, "Number of days without a hosted scheduled event after which a member is considered inactive")
+	cmd.Flags().StringSliceVar(&allowList, "allow-list", nil, "Emails of members that are never reclaimed")
+	cmd.Flags().StringVar(&reportFile, "report-file", "", "Path of the report file. The report is written to stdout when empty")
+	cmd.Flags().StringVar(&reportFormat, "report-format", reportFormatJSON, "Format of the report: json, csv")
+	cmd.Flags().BoolVar(&confirm, "confirm", false, "Remove the inactive members instead of only reporting them")
+
+	return cmd
+}
+
+func writeReclaimCSV(w io.Writer, results []*connector.ReclaimResult) error {
+	cw := csv.NewWriter(w)
+	err := cw.Write([]string{"email", "name", "role", "user_uri", "membership_uri", "removed", "error"})
+	if err != nil {
+		return err
+	}
+
+	for _, r := range results {
+		err := cw.Write([]string{r.Email, r.Name, r.Role, r.UserURI, r.MembershipURI, strconv.FormatBool(r.Removed), r.Error})
+		if err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+
+	return cw.Error()
+}
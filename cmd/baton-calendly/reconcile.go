@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/conductorone/baton-calendly/pkg/connector"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+func newReconcileCommand(ctx context.Context, v *viper.Viper) *cobra.Command {
+	var (
+		stateFile  string
+		reportFile string
+		apply      bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "reconcile",
+		Short: "Reconcile organization memberships with a desired state file",
+		Long: "Compares a YAML or JSON file of emails and their desired roles (member, admin, pending) per organization " +
+			"with live memberships and invitations and prints the plan. Without --apply the command exits with an error " +
+			"when there is any drift. With --apply the invitations and removals are performed.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			state, err := readDesiredState(stateFile)
+			if err != nil {
+				return err
+			}
+
+			runCtx, err := initCommand(ctx, v)
+			if err != nil {
+				return err
+			}
+
+			cb, err := newCalendly(runCtx, v)
+			if err != nil {
+				return err
+			}
+
+			// opened first, so memberships aren't changed when the report can't be written
+			var out io.WriteCloser
+			if reportFile != "" {
+				out, err = openOutput(reportFile)
+				if err != nil {
+					return err
+				}
+				defer out.Close()
+			}
+
+			actions, err := cb.PlanReconcile(runCtx, state)
+			if err != nil {
+				return err
+			}
+
+			w := cmd.OutOrStdout()
+			if len(actions) == 0 {
+				fmt.Fprintln(w, "No changes, memberships match the desired state.")
+			} else {
+				fmt.Fprintf(w, "%d changes:\n", len(actions))
+				for _, a := range actions {
+					fmt.Fprintf(w, "  %s %s\n", a.Organization, a)
+				}
+			}
+
+			if apply {
+				cb.ApplyReconcile(runCtx, actions)
+			}
+
+			if out != nil {
+				if actions == nil {
+					actions = []*connector.ReconcileAction{}
+				}
+
+				err = writeJSON(out, actions)
+				if err != nil {
+					return err
+				}
+			}
+
+			if len(actions) == 0 {
+				return nil
+			}
+
+			if !apply {
+				return fmt.Errorf("drift detected: %d changes, run with --apply to reconcile", len(actions))
+			}
+
+			unresolved := 0
+			for _, a := range actions {
+				if !a.Applied {
+					unresolved++
+				}
+			}
+
+			if unresolved > 0 {
+				return fmt.Errorf("%d changes could not be applied", unresolved)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&stateFile, "state-file", "", "Path of the YAML or JSON desired state file")
+	cmd.Flags().StringVar(&reportFile, "report-file", "", "Path of the JSON report of the changes and their outcome")
+	cmd.Flags().BoolVar(&apply, "apply", false, "Apply the changes instead of only checking for drift")
+	_ = cmd.MarkFlagRequired("state-file")
+
+	return cmd
+}
+
+// readDesiredState parses the desired state file. JSON is a subset of YAML, so both are read by the YAML decoder.
+func readDesiredState(path string) (*connector.DesiredState, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read desired state file: %w", err)
+	}
+
+	var state connector.DesiredState
+	if err := yaml.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse desired state file: %w", err)
+	}
+
+	if len(state.Organizations) == 0 {
+		return nil, fmt.Errorf("desired state file doesn't list any organization")
+	}
+
+	return &state, nil
+}
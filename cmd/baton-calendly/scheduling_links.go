@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func newCreateSchedulingLinkCommand(ctx context.Context, v *viper.Viper) *cobra.Command {
+	var (
+		eventType     string
+		maxEventCount int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "create-scheduling-link",
+		Short: "Create a limited use booking link for an event type",
+		Long:  "Creates a booking link for the event type that expires after the given number of booked events. Requires --provisioning.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !v.GetBool("provisioning") {
+				return fmt.Errorf("provisioning must be enabled to create scheduling links")
+			}
+
+			runCtx, err := initCommand(ctx, v)
+			if err != nil {
+				return err
+			}
+
+			cb, err := newCalendly(runCtx, v)
+			if err != nil {
+				return err
+			}
+
+			url, err := cb.CreateSchedulingLink(runCtx, eventType, maxEventCount)
+			if err != nil {
+				return err
+			}
+
+			_, err = fmt.Fprintln(cmd.OutOrStdout(), url)
+
+			return err
+		},
+	}
+
+	cmd.Flags().StringVar(&eventType, "event-type", "", "Resource ID of the event type, its Calendly URI")
+	cmd.Flags().IntVar(&maxEventCount, "max-event-count", 1, "Number of events that can be booked using the link")
+	_ = cmd.MarkFlagRequired("event-type")
+
+	return cmd
+}
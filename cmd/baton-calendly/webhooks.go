@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+func newServeWebhooksCommand(ctx context.Context, v *viper.Viper) *cobra.Command {
+	var (
+		listenAddress    string
+		path             string
+		toleranceSeconds int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "serve-webhooks",
+		Short: "Receive Calendly webhooks and buffer them for the event feed",
+		Long: "Runs an HTTP listener verifying signatures of Calendly webhooks. Invitee and routing form submission " +
+			"webhooks are appended to the webhook events file, from which the connector serves usage events.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			runCtx, err := initCommand(ctx, v)
+			if err != nil {
+				return err
+			}
+
+			cb, err := newCalendly(runCtx, v)
+			if err != nil {
+				return err
+			}
+
+			handler, err := cb.WebhookHandler(runCtx, v.GetString(webhookSigningKey), time.Duration(toleranceSeconds)*time.Second)
+			if err != nil {
+				return err
+			}
+
+			mux := http.NewServeMux()
+			mux.Handle(path, handler)
+
+			server := &http.Server{
+				Addr:              listenAddress,
+				Handler:           mux,
+				ReadHeaderTimeout: 10 * time.Second,
+			}
+
+			runCtx, stop := signal.NotifyContext(runCtx, os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			go func() {
+				<-runCtx.Done()
+
+				shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+				defer cancel()
+				_ = server.Shutdown(shutdownCtx)
+			}()
+
+			ctxzap.Extract(runCtx).Info("listening for Calendly webhooks", zap.String("address", listenAddress), zap.String("path", path))
+
+			err = server.ListenAndServe()
+			if err != nil && !errors.Is(err, http.ErrServerClosed) {
+				return fmt.Errorf("webhook listener failed: %w", err)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&listenAddress, "listen-address", ":8080", "Address the webhook listener binds to")
+	cmd.Flags().StringVar(&path, "path", "/calendly/webhooks", "URL path of the webhook callback")
+	cmd.Flags().IntVar(&toleranceSeconds, "tolerance-seconds", 180, "Maximum age of the signature timestamp in seconds. Disabled when 0")
+
+	return cmd
+}
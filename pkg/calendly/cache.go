@@ -0,0 +1,109 @@
+package calendly
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+	"time"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/uhttp"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+)
+
+// lookupCache keeps responses of lookups that don't change during a sync, like the current user,
+// or organization details. It is cleared on every write request.
+type lookupCache struct {
+	store  uhttp.GoCache
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+func newLookupCache(ctx context.Context, ttl time.Duration) (*lookupCache, error) {
+	l := ctxzap.Extract(ctx)
+
+	store, err := uhttp.NewGoCache(ctx, uhttp.CacheConfig{
+		LogDebug: l.Level().Enabled(zap.DebugLevel),
+		CacheTTL: int32(ttl.Seconds()),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &lookupCache{
+		store: store,
+	}, nil
+}
+
+// getCached is like get but serves repeated requests from the lookup cache.
+func (c *Client) getCached(ctx context.Context, urlAddress *url.URL, response interface{}) (*v2.RateLimitDescription, error) {
+	if c.cache == nil {
+		return c.get(ctx, urlAddress, response, nil)
+	}
+
+	l := ctxzap.Extract(ctx)
+
+	req, err := c.createRequest(ctx, http.MethodGet, urlAddress, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := uhttp.CreateCacheKey(req)
+	if err != nil {
+		return nil, err
+	}
+
+	cached, err := c.cache.store.Get(key)
+	if err == nil && cached != nil {
+		defer cached.Body.Close()
+
+		if err := json.NewDecoder(cached.Body).Decode(response); err == nil {
+			c.cache.hits.Add(1)
+			l.Debug(
+				"calendly lookup cache hit",
+				zap.String("url", urlAddress.String()),
+				zap.Int64("hits", c.cache.hits.Load()),
+				zap.Int64("misses", c.cache.misses.Load()),
+			)
+
+			return nil, nil
+		}
+	}
+
+	c.cache.misses.Add(1)
+	l.Debug(
+		"calendly lookup cache miss",
+		zap.String("url", urlAddress.String()),
+		zap.Int64("hits", c.cache.hits.Load()),
+		zap.Int64("misses", c.cache.misses.Load()),
+	)
+
+	rldata := &v2.RateLimitDescription{}
+	resp, err := c.wrapper.Do(req, uhttp.WithJSONResponse(response), WithErrorResponse(&ErrorResponse{}), WithRatelimitData(rldata))
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+	c.setRateLimit(rldata)
+
+	if err := c.cache.store.Set(key, resp); err != nil {
+		l.Debug("calendly lookup cache set failed", zap.String("url", urlAddress.String()), zap.Error(err))
+	}
+
+	return rldata, nil
+}
+
+// invalidateLookups drops all cached lookups after a write request.
+func (c *Client) invalidateLookups(ctx context.Context) {
+	if c.cache == nil {
+		return
+	}
+
+	if err := c.cache.store.Clear(); err != nil {
+		ctxzap.Extract(ctx).Debug("calendly lookup cache clear failed", zap.Error(err))
+	}
+}
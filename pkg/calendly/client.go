@@ -8,6 +8,7 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
@@ -25,21 +26,72 @@ const (
 	OrgInvitesEndpoint    = "/invitations"
 
 	UserEndpoint = "/users/%s"
+
+	GroupsEndpoint               = "/groups"
+	GroupRelationshipsEndpoint   = "/group_relationships"
+	RoutingFormsEndpoint         = "/routing_forms"
+	ScheduledEventsEndpoint      = "/scheduled_events"
+	CancellationEndpoint         = "/cancellation"
+	EventTypesEndpoint           = "/event_types"
+	EventTypeMembershipsEndpoint = "/event_type_memberships"
+	SchedulingLinksEndpoint      = "/scheduling_links"
+	WebhookSubscriptionsEndpoint = "/webhook_subscriptions"
+
+	InviteeDataDeletionEndpoint = "/data_compliance/deletion/invitees"
+	EventDataDeletionEndpoint   = "/data_compliance/deletion/events"
+)
+
+const (
+	// MaxInviteeDataDeletionEmails is the maximum number of emails accepted by a single invitee data deletion request.
+	MaxInviteeDataDeletionEmails = 350
+
+	// MaxEventDataDeletionRange is the longest time window, 24 months, accepted by a single scheduled event data deletion request.
+	MaxEventDataDeletionRange = 2 * 365 * 24 * time.Hour
 )
 
+// MaxPageSize is the maximum number of items Calendly returns in a single page.
+const MaxPageSize = 100
+
 type Client struct {
 	wrapper *uhttp.BaseHttpClient
 	baseURL *url.URL
+
+	mu        sync.Mutex
+	rateLimit *v2.RateLimitDescription
+
+	cache *lookupCache
 }
 
-func NewClient(httpClient *http.Client) *Client {
-	return &Client{
-		wrapper: uhttp.NewBaseHttpClient(httpClient),
+// NewClient creates Calendly API client. Lookups of the current user and organizations
+// are cached for the lookupCacheTTL, zero disables the cache.
+func NewClient(ctx context.Context, httpClient *http.Client, lookupCacheTTL time.Duration) (*Client, error) {
+	// the response cache of the wrapper isn't cleared on writes, the lookup cache is the only one
+	wrapper, err := uhttp.NewBaseHttpClientWithContext(
+		context.WithValue(ctx, uhttp.ContextKey{}, uhttp.CacheConfig{DisableCache: true}),
+		httpClient,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Client{
+		wrapper: wrapper,
 		baseURL: &url.URL{
 			Scheme: "https",
 			Host:   BaseHost,
 		},
 	}
+
+	if lookupCacheTTL > 0 {
+		cache, err := newLookupCache(ctx, lookupCacheTTL)
+		if err != nil {
+			return nil, err
+		}
+
+		c.cache = cache
+	}
+
+	return c, nil
 }
 
 type PaginationVars struct {
@@ -55,7 +107,8 @@ func NewPaginationVars(count int, next string) *PaginationVars {
 }
 
 type FilterVars struct {
-	Email string `json:"email"`
+	Email   string `json:"email"`
+	UserURI string `json:"user"`
 }
 
 func NewFilterVars(email string) *FilterVars {
@@ -64,6 +117,42 @@ func NewFilterVars(email string) *FilterVars {
 	}
 }
 
+func NewUserFilterVars(userURI string) *FilterVars {
+	return &FilterVars{
+		UserURI: userURI,
+	}
+}
+
+type EventFilterVars struct {
+	UserURI      string
+	Status       string
+	MinStartTime time.Time
+	MaxStartTime time.Time
+	Sort         string
+}
+
+func (f *EventFilterVars) apply(vals *url.Values) {
+	if f.UserURI != "" {
+		vals.Set("user", f.UserURI)
+	}
+
+	if f.Status != "" {
+		vals.Set("status", f.Status)
+	}
+
+	if !f.MinStartTime.IsZero() {
+		vals.Set("min_start_time", f.MinStartTime.UTC().Format(time.RFC3339))
+	}
+
+	if !f.MaxStartTime.IsZero() {
+		vals.Set("max_start_time", f.MaxStartTime.UTC().Format(time.RFC3339))
+	}
+
+	if f.Sort != "" {
+		vals.Set("sort", f.Sort)
+	}
+}
+
 type ListResponse[T any] struct {
 	Collection []T             `json:"collection"`
 	Pagination *PaginationVars `json:"pagination"`
@@ -102,7 +191,7 @@ func (c *Client) GetCurrentUser(ctx context.Context) (*User, *v2.RateLimitDescri
 	u := c.prepareURL(fmt.Sprintf(UserEndpoint, "me"))
 
 	var res SingleResponse[User]
-	rldata, err := c.get(ctx, u, &res, nil)
+	rldata, err := c.getCached(ctx, u, &res)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -117,7 +206,13 @@ func (c *Client) ListUsersUnderOrg(ctx context.Context, orgURI string, pgVars *P
 	queryParams.Set("organization", orgURI)
 
 	if filterVars != nil {
-		queryParams.Set("email", filterVars.Email)
+		if filterVars.Email != "" {
+			queryParams.Set("email", filterVars.Email)
+		}
+
+		if filterVars.UserURI != "" {
+			queryParams.Set("user", filterVars.UserURI)
+		}
 	}
 
 	var res ListResponse[OrgMembership]
@@ -137,7 +232,7 @@ func (c *Client) GetOrgDetails(ctx context.Context, orgURI string) (*Organizatio
 		return nil, nil, err
 	}
 
-	rldata, err := c.get(ctx, u, &res, nil)
+	rldata, err := c.getCached(ctx, u, &res)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -170,10 +265,11 @@ func (c *Client) InviteOrgMember(ctx context.Context, orgURI string, email strin
 		Email: email,
 	}
 
-	return c.post(ctx, u, body, nil)
+	return c.post(ctx, u, body, nil, nil)
 }
 
-func (c *Client) ListUserInvitations(ctx context.Context, orgURI string, pgVars *PaginationVars, filterVars *FilterVars) ([]Invitation, string, *v2.RateLimitDescription, error) {
+// ListUserInvitations returns invitations of the organization with the status, empty status returns all of them.
+func (c *Client) ListUserInvitations(ctx context.Context, orgURI, status string, pgVars *PaginationVars, filterVars *FilterVars) ([]Invitation, string, *v2.RateLimitDescription, error) {
 	path, err := url.JoinPath(orgURI, OrgInvitesEndpoint)
 	if err != nil {
 		return nil, "", nil, err
@@ -186,9 +282,11 @@ func (c *Client) ListUserInvitations(ctx context.Context, orgURI string, pgVars
 
 	queryParams := &url.Values{}
 	c.prepareQuery(queryParams, pgVars)
-	queryParams.Set("status", "pending")
+	if status != "" {
+		queryParams.Set("status", status)
+	}
 
-	if filterVars != nil {
+	if filterVars != nil && filterVars.Email != "" {
 		queryParams.Set("email", filterVars.Email)
 	}
 
@@ -215,57 +313,345 @@ func (c *Client) RemoveUserInvitation(ctx context.Context, orgURI, invitationID
 	return c.delete(ctx, u, nil)
 }
 
+func (c *Client) ListRoutingForms(ctx context.Context, orgURI string, pgVars *PaginationVars) ([]RoutingForm, string, *v2.RateLimitDescription, error) {
+	u := c.prepareURL(RoutingFormsEndpoint)
+	queryParams := &url.Values{}
+	c.prepareQuery(queryParams, pgVars)
+	queryParams.Set("organization", orgURI)
+
+	var res ListResponse[RoutingForm]
+	rldata, err := c.get(ctx, u, &res, queryParams)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	return res.Collection, res.Pagination.Next, rldata, nil
+}
+
+func (c *Client) ListGroups(ctx context.Context, orgURI string, pgVars *PaginationVars) ([]Group, string, *v2.RateLimitDescription, error) {
+	u := c.prepareURL(GroupsEndpoint)
+	queryParams := &url.Values{}
+	c.prepareQuery(queryParams, pgVars)
+	queryParams.Set("organization", orgURI)
+
+	var res ListResponse[Group]
+	rldata, err := c.get(ctx, u, &res, queryParams)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	return res.Collection, res.Pagination.Next, rldata, nil
+}
+
+func (c *Client) ListGroupRelationships(ctx context.Context, orgURI, groupURI string, pgVars *PaginationVars) ([]GroupRelationship, string, *v2.RateLimitDescription, error) {
+	u := c.prepareURL(GroupRelationshipsEndpoint)
+	queryParams := &url.Values{}
+	c.prepareQuery(queryParams, pgVars)
+	queryParams.Set("organization", orgURI)
+	queryParams.Set("group", groupURI)
+
+	var res ListResponse[GroupRelationship]
+	rldata, err := c.get(ctx, u, &res, queryParams)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	return res.Collection, res.Pagination.Next, rldata, nil
+}
+
+func (c *Client) ListEventTypes(ctx context.Context, orgURI string, pgVars *PaginationVars) ([]EventType, string, *v2.RateLimitDescription, error) {
+	u := c.prepareURL(EventTypesEndpoint)
+	queryParams := &url.Values{}
+	c.prepareQuery(queryParams, pgVars)
+	queryParams.Set("organization", orgURI)
+
+	var res ListResponse[EventType]
+	rldata, err := c.get(ctx, u, &res, queryParams)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	return res.Collection, res.Pagination.Next, rldata, nil
+}
+
+// ListUserEventTypes returns event types of the user, including the ones the user only hosts.
+func (c *Client) ListUserEventTypes(ctx context.Context, userURI string, pgVars *PaginationVars) ([]EventType, string, *v2.RateLimitDescription, error) {
+	u := c.prepareURL(EventTypesEndpoint)
+	queryParams := &url.Values{}
+	c.prepareQuery(queryParams, pgVars)
+	queryParams.Set("user", userURI)
+
+	var res ListResponse[EventType]
+	rldata, err := c.get(ctx, u, &res, queryParams)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	return res.Collection, res.Pagination.Next, rldata, nil
+}
+
+func (c *Client) ListEventTypeHosts(ctx context.Context, eventTypeURI string, pgVars *PaginationVars) ([]EventTypeHost, string, *v2.RateLimitDescription, error) {
+	u := c.prepareURL(EventTypeMembershipsEndpoint)
+	queryParams := &url.Values{}
+	c.prepareQuery(queryParams, pgVars)
+	queryParams.Set("event_type", eventTypeURI)
+
+	var res ListResponse[EventTypeHost]
+	rldata, err := c.get(ctx, u, &res, queryParams)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	return res.Collection, res.Pagination.Next, rldata, nil
+}
+
+type SchedulingLinkBody struct {
+	MaxEventCount int    `json:"max_event_count"`
+	Owner         string `json:"owner"`
+	OwnerType     string `json:"owner_type"`
+}
+
+// CreateSchedulingLink creates a booking link for the event type that expires after maxEventCount events are booked.
+func (c *Client) CreateSchedulingLink(ctx context.Context, eventTypeURI string, maxEventCount int) (*SchedulingLink, *v2.RateLimitDescription, error) {
+	u := c.prepareURL(SchedulingLinksEndpoint)
+
+	body := &SchedulingLinkBody{
+		MaxEventCount: maxEventCount,
+		Owner:         eventTypeURI,
+		OwnerType:     SchedulingLinkOwnerEventType,
+	}
+
+	var res SingleResponse[SchedulingLink]
+	rldata, err := c.post(ctx, u, body, &res, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &res.Resource, rldata, nil
+}
+
+func (c *Client) ListScheduledEvents(ctx context.Context, orgURI string, pgVars *PaginationVars, filterVars *EventFilterVars) ([]ScheduledEvent, string, *v2.RateLimitDescription, error) {
+	u := c.prepareURL(ScheduledEventsEndpoint)
+	queryParams := &url.Values{}
+	c.prepareQuery(queryParams, pgVars)
+	queryParams.Set("organization", orgURI)
+
+	if filterVars != nil {
+		filterVars.apply(queryParams)
+	}
+
+	var res ListResponse[ScheduledEvent]
+	rldata, err := c.get(ctx, u, &res, queryParams)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	return res.Collection, res.Pagination.Next, rldata, nil
+}
+
+// ListWebhookSubscriptions returns webhook subscriptions of the organization with the scope.
+func (c *Client) ListWebhookSubscriptions(ctx context.Context, orgURI, scope string, pgVars *PaginationVars) ([]WebhookSubscription, string, *v2.RateLimitDescription, error) {
+	u := c.prepareURL(WebhookSubscriptionsEndpoint)
+	queryParams := &url.Values{}
+	c.prepareQuery(queryParams, pgVars)
+	queryParams.Set("organization", orgURI)
+	queryParams.Set("scope", scope)
+
+	var res ListResponse[WebhookSubscription]
+	rldata, err := c.get(ctx, u, &res, queryParams)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	return res.Collection, res.Pagination.Next, rldata, nil
+}
+
+func (c *Client) GetWebhookSubscription(ctx context.Context, subscriptionURI string) (*WebhookSubscription, *v2.RateLimitDescription, error) {
+	u, err := url.Parse(subscriptionURI)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var res SingleResponse[WebhookSubscription]
+	rldata, err := c.get(ctx, u, &res, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &res.Resource, rldata, nil
+}
+
+type WebhookSubscriptionBody struct {
+	URL          string   `json:"url"`
+	Events       []string `json:"events"`
+	Organization string   `json:"organization"`
+	User         string   `json:"user,omitempty"`
+	Group        string   `json:"group,omitempty"`
+	Scope        string   `json:"scope"`
+	SigningKey   string   `json:"signing_key,omitempty"`
+}
+
+// CreateWebhookSubscription creates a webhook subscription. Deliveries are signed with the signing key.
+func (c *Client) CreateWebhookSubscription(ctx context.Context, body *WebhookSubscriptionBody) (*WebhookSubscription, *v2.RateLimitDescription, error) {
+	u := c.prepareURL(WebhookSubscriptionsEndpoint)
+
+	var res SingleResponse[WebhookSubscription]
+	rldata, err := c.post(ctx, u, body, &res, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &res.Resource, rldata, nil
+}
+
+func (c *Client) DeleteWebhookSubscription(ctx context.Context, subscriptionURI string) (*v2.RateLimitDescription, error) {
+	u, err := url.Parse(subscriptionURI)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.delete(ctx, u, nil)
+}
+
+type CancellationBody struct {
+	Reason string `json:"reason,omitempty"`
+}
+
+// CancelScheduledEvent cancels the scheduled event, notifying its invitees with the reason.
+func (c *Client) CancelScheduledEvent(ctx context.Context, eventURI, reason string) (*v2.RateLimitDescription, error) {
+	path, err := url.JoinPath(eventURI, CancellationEndpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := url.Parse(path)
+	if err != nil {
+		return nil, err
+	}
+
+	body := &CancellationBody{
+		Reason: reason,
+	}
+
+	return c.post(ctx, u, body, nil, nil)
+}
+
+type InviteeDataDeletionBody struct {
+	Emails []string `json:"emails"`
+}
+
+// DeleteInviteeData requests removal of all invitee data associated with the emails.
+// Calendly processes the deletion asynchronously and doesn't return any job reference.
+func (c *Client) DeleteInviteeData(ctx context.Context, emails []string) (*v2.RateLimitDescription, error) {
+	u := c.prepareURL(InviteeDataDeletionEndpoint)
+
+	body := &InviteeDataDeletionBody{
+		Emails: emails,
+	}
+
+	return c.post(ctx, u, body, nil, nil)
+}
+
+type EventDataDeletionBody struct {
+	StartTime string `json:"start_time"`
+	EndTime   string `json:"end_time"`
+}
+
+// DeleteEventData requests removal of scheduled event data within the time window.
+// Calendly processes the deletion asynchronously and doesn't return any job reference.
+func (c *Client) DeleteEventData(ctx context.Context, start, end time.Time) (*v2.RateLimitDescription, error) {
+	u := c.prepareURL(EventDataDeletionEndpoint)
+
+	body := &EventDataDeletionBody{
+		StartTime: start.UTC().Format(time.RFC3339),
+		EndTime:   end.UTC().Format(time.RFC3339),
+	}
+
+	return c.post(ctx, u, body, nil, nil)
+}
+
 func (c *Client) get(ctx context.Context, urlAddress *url.URL, response interface{}, queryParams *url.Values) (*v2.RateLimitDescription, error) {
 	req, err := c.createRequest(ctx, http.MethodGet, urlAddress, nil, queryParams)
 	if err != nil {
 		return nil, err
 	}
 
-	var rldata *v2.RateLimitDescription
+	rldata := &v2.RateLimitDescription{}
 	resp, err := c.wrapper.Do(req, uhttp.WithJSONResponse(response), WithErrorResponse(&ErrorResponse{}), WithRatelimitData(rldata))
 	if err != nil {
 		return nil, err
 	}
 
 	defer resp.Body.Close()
+	c.setRateLimit(rldata)
 
 	return rldata, nil
 }
 
 func (c *Client) delete(ctx context.Context, urlAddress *url.URL, queryParams *url.Values) (*v2.RateLimitDescription, error) {
+	c.invalidateLookups(ctx)
+
 	req, err := c.createRequest(ctx, http.MethodDelete, urlAddress, nil, queryParams)
 	if err != nil {
 		return nil, err
 	}
 
-	var rldata *v2.RateLimitDescription
+	rldata := &v2.RateLimitDescription{}
 	resp, err := c.wrapper.Do(req, WithErrorResponse(&ErrorResponse{}), WithRatelimitData(rldata))
 	if err != nil {
 		return nil, err
 	}
 
 	defer resp.Body.Close()
+	c.setRateLimit(rldata)
 
 	return rldata, nil
 }
 
-func (c *Client) post(ctx context.Context, urlAddress *url.URL, body interface{}, queryParams *url.Values) (*v2.RateLimitDescription, error) {
+// post sends the body and decodes the response into response, unless it is nil.
+func (c *Client) post(ctx context.Context, urlAddress *url.URL, body interface{}, response interface{}, queryParams *url.Values) (*v2.RateLimitDescription, error) {
+	c.invalidateLookups(ctx)
+
 	req, err := c.createRequest(ctx, http.MethodPost, urlAddress, body, queryParams)
 	if err != nil {
 		return nil, err
 	}
 
-	var rldata *v2.RateLimitDescription
-	resp, err := c.wrapper.Do(req, WithErrorResponse(&ErrorResponse{}), WithRatelimitData(rldata))
+	rldata := &v2.RateLimitDescription{}
+	options := []uhttp.DoOption{WithErrorResponse(&ErrorResponse{}), WithRatelimitData(rldata)}
+	if response != nil {
+		options = append(options, uhttp.WithJSONResponse(response))
+	}
+
+	resp, err := c.wrapper.Do(req, options...)
 	if err != nil {
 		return nil, err
 	}
 
 	defer resp.Body.Close()
+	c.setRateLimit(rldata)
 
 	return rldata, nil
 }
 
+func (c *Client) setRateLimit(rl *v2.RateLimitDescription) {
+	if rl.Limit == 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rateLimit = rl
+}
+
+// RateLimit returns rate limit data from the most recent response carrying rate limit headers.
+func (c *Client) RateLimit() *v2.RateLimitDescription {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.rateLimit
+}
+
 func (c *Client) createRequest(ctx context.Context, method string, urlAddress *url.URL, body interface{}, queryParams *url.Values) (*http.Request, error) {
 	req, err := c.wrapper.NewRequest(
 		ctx,
@@ -291,11 +677,9 @@ func WithRatelimitData(resource *v2.RateLimitDescription) uhttp.DoOption {
 			return err
 		}
 
-		resource = &v2.RateLimitDescription{
-			Limit:     rl.Limit,
-			Remaining: rl.Remaining,
-			ResetAt:   rl.ResetAt,
-		}
+		resource.Limit = rl.Limit
+		resource.Remaining = rl.Remaining
+		resource.ResetAt = rl.ResetAt
 
 		return nil
 	}
@@ -307,19 +691,39 @@ func WithErrorResponse(resource *ErrorResponse) uhttp.DoOption {
 			body := strings.NewReader(string(resp.Body))
 			// Decode the JSON response body into the ErrorResponse struct
 			if err := json.NewDecoder(body).Decode(&resource); err != nil {
-				return status.Error(codes.Unknown, "Request failed with unknown error")
+				return status.Error(ErrorCode(resp.StatusCode), "Request failed with unknown error")
 			}
 
 			// Construct a more detailed error message
 			errMsg := fmt.Sprintf("Request failed with status %d: %s", resp.StatusCode, resource.Message)
 
-			return status.Error(codes.Unknown, errMsg)
+			return status.Error(ErrorCode(resp.StatusCode), errMsg)
 		}
 
 		return nil
 	}
 }
 
+// ErrorCode maps HTTP status code of a failed response to gRPC code.
+func ErrorCode(statusCode int) codes.Code {
+	switch statusCode {
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.AlreadyExists
+	case http.StatusTooManyRequests:
+		return codes.Unavailable
+	default:
+		return codes.Unknown
+	}
+}
+
 func extractRateLimitData(resp *uhttp.WrapperResponse) (*v2.RateLimitDescription, error) {
 	if resp == nil {
 		return nil, nil
@@ -6,16 +6,28 @@ type User struct {
 	FullName  string `json:"name"`
 	Slug      string `json:"slug"`
 	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
 	OrgURI    string `json:"current_organization"`
 }
 
 type OrgMembership struct {
-	Org  string `json:"organization"`
-	Role string `json:"role"`
-	ID   string `json:"uri"`
-	User *User  `json:"user"`
+	Org       string `json:"organization"`
+	Role      string `json:"role"`
+	ID        string `json:"uri"`
+	User      *User  `json:"user"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
 }
 
+const (
+	PlanBasic        = "basic"
+	PlanEssentials   = "essentials"
+	PlanStandard     = "standard"
+	PlanProfessional = "professional"
+	PlanTeams        = "teams"
+	PlanEnterprise   = "enterprise"
+)
+
 type Organization struct {
 	ID        string `json:"uri"`
 	CreatedAt string `json:"created_at"`
@@ -23,10 +35,154 @@ type Organization struct {
 	Stage     string `json:"stage"`
 }
 
+const (
+	InvitationStatusPending  = "pending"
+	InvitationStatusAccepted = "accepted"
+	InvitationStatusDeclined = "declined"
+)
+
+// Invitation to the organization. UserID links the user who accepted it, UpdatedAt of an accepted
+// invitation is the time of the acceptance.
 type Invitation struct {
-	ID        string `json:"uri"`
-	Email     string `json:"email"`
-	Status    string `json:"status"`
-	CreatedAt string `json:"created_at"`
-	UserID    string `json:"user"`
+	ID         string `json:"uri"`
+	Email      string `json:"email"`
+	Status     string `json:"status"`
+	CreatedAt  string `json:"created_at"`
+	UpdatedAt  string `json:"updated_at"`
+	LastSentAt string `json:"last_sent_at"`
+	UserID     string `json:"user"`
+}
+
+const (
+	GroupRoleMember = "member"
+	GroupRoleAdmin  = "admin"
+)
+
+type Group struct {
+	ID          string `json:"uri"`
+	Name        string `json:"name"`
+	Org         string `json:"organization"`
+	MemberCount int    `json:"member_count"`
+	CreatedAt   string `json:"created_at"`
+	UpdatedAt   string `json:"updated_at"`
+}
+
+// GroupRelationship links a group with its member or admin. The owner is either an organization member or a pending invitation.
+type GroupRelationship struct {
+	ID    string              `json:"uri"`
+	Role  string              `json:"role"`
+	Org   string              `json:"organization"`
+	Group string              `json:"group"`
+	Owner *GroupRelationOwner `json:"owner"`
+}
+
+type GroupRelationOwner struct {
+	ID    string `json:"uri"`
+	Type  string `json:"type"`
+	Email string `json:"email"`
+	Name  string `json:"name"`
+}
+
+type RoutingForm struct {
+	ID        string                `json:"uri"`
+	Org       string                `json:"organization"`
+	Name      string                `json:"name"`
+	Status    string                `json:"status"`
+	Questions []RoutingFormQuestion `json:"questions"`
+	CreatedAt string                `json:"created_at"`
+	UpdatedAt string                `json:"updated_at"`
+}
+
+type RoutingFormQuestion struct {
+	ID       string `json:"uuid"`
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Required bool   `json:"required"`
+}
+
+const (
+	EventTypeKindSolo  = "solo"
+	EventTypeKindGroup = "group"
+
+	PoolingTypeRoundRobin = "round_robin"
+	PoolingTypeCollective = "collective"
+)
+
+type EventType struct {
+	ID            string            `json:"uri"`
+	Name          string            `json:"name"`
+	Slug          string            `json:"slug"`
+	Active        bool              `json:"active"`
+	Kind          string            `json:"kind"`
+	PoolingType   string            `json:"pooling_type"`
+	Type          string            `json:"type"`
+	AdminManaged  bool              `json:"admin_managed"`
+	Duration      int               `json:"duration"`
+	SchedulingURL string            `json:"scheduling_url"`
+	Profile       *EventTypeProfile `json:"profile"`
+	CreatedAt     string            `json:"created_at"`
+	UpdatedAt     string            `json:"updated_at"`
+}
+
+type EventTypeProfile struct {
+	Type  string `json:"type"`
+	Name  string `json:"name"`
+	Owner string `json:"owner"`
+}
+
+// EventTypeHost is a user assigned as a host of the event type.
+type EventTypeHost struct {
+	EventType string `json:"event_type"`
+	Member    *User  `json:"member"`
+}
+
+const SchedulingLinkOwnerEventType = "EventType"
+
+type SchedulingLink struct {
+	BookingURL string `json:"booking_url"`
+	Owner      string `json:"owner"`
+	OwnerType  string `json:"owner_type"`
+}
+
+const (
+	ScheduledEventStatusActive   = "active"
+	ScheduledEventStatusCanceled = "canceled"
+)
+
+type ScheduledEvent struct {
+	ID          string            `json:"uri"`
+	Name        string            `json:"name"`
+	Status      string            `json:"status"`
+	StartTime   string            `json:"start_time"`
+	EndTime     string            `json:"end_time"`
+	EventType   string            `json:"event_type"`
+	CreatedAt   string            `json:"created_at"`
+	UpdatedAt   string            `json:"updated_at"`
+	Memberships []EventMembership `json:"event_memberships"`
+}
+
+type EventMembership struct {
+	User      string `json:"user"`
+	UserEmail string `json:"user_email"`
+	UserName  string `json:"user_name"`
+}
+
+const (
+	WebhookScopeOrganization = "organization"
+	WebhookScopeUser         = "user"
+	WebhookScopeGroup        = "group"
+)
+
+type WebhookSubscription struct {
+	ID           string   `json:"uri"`
+	CallbackURL  string   `json:"callback_url"`
+	State        string   `json:"state"`
+	Events       []string `json:"events"`
+	Scope        string   `json:"scope"`
+	Organization string   `json:"organization"`
+	User         string   `json:"user"`
+	Group        string   `json:"group"`
+	Creator      string   `json:"creator"`
+	CreatedAt    string   `json:"created_at"`
+	UpdatedAt    string   `json:"updated_at"`
 }
@@ -0,0 +1,186 @@
+package calendly
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// WebhookSignatureHeader carries the timestamp and HMAC signature of a webhook request, e.g. t=1492774577,v1=5257a8...
+	WebhookSignatureHeader = "Calendly-Webhook-Signature"
+
+	WebhookInviteeCreated         = "invitee.created"
+	WebhookInviteeCanceled        = "invitee.canceled"
+	WebhookRoutingFormSubmission  = "routing_form_submission.created"
+	webhookSignatureVersion       = "v1"
+	webhookSignatureTimestampPart = "t"
+)
+
+var (
+	ErrWebhookSignatureMissing = errors.New("webhook signature missing")
+	ErrWebhookSignatureInvalid = errors.New("webhook signature invalid")
+	ErrWebhookSignatureExpired = errors.New("webhook signature timestamp outside of the tolerance window")
+)
+
+// VerifyWebhookSignature checks the signature header of the webhook body against the signing key.
+// The signature is HMAC SHA256 of the timestamp and the body joined by a dot. The timestamp must be
+// within the tolerance of now, zero tolerance skips the check.
+func VerifyWebhookSignature(header string, body []byte, signingKey string, tolerance time.Duration, now time.Time) error {
+	if header == "" {
+		return ErrWebhookSignatureMissing
+	}
+
+	var timestamp, signature string
+	for _, part := range strings.Split(header, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+
+		switch key {
+		case webhookSignatureTimestampPart:
+			timestamp = value
+		case webhookSignatureVersion:
+			signature = value
+		}
+	}
+
+	if timestamp == "" || signature == "" {
+		return ErrWebhookSignatureMissing
+	}
+
+	mac := hmac.New(sha256.New, []byte(signingKey))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	expected := hex.EncodeToString(mac.Sum(nil))
+
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return ErrWebhookSignatureInvalid
+	}
+
+	if tolerance > 0 {
+		ts, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil {
+			return ErrWebhookSignatureInvalid
+		}
+
+		if d := now.Sub(time.Unix(ts, 0)); d > tolerance || d < -tolerance {
+			return ErrWebhookSignatureExpired
+		}
+	}
+
+	return nil
+}
+
+// WebhookEvent is the envelope of every webhook delivery. Payload is decoded by the event specific methods.
+type WebhookEvent struct {
+	Event     string          `json:"event"`
+	CreatedAt string          `json:"created_at"`
+	CreatedBy string          `json:"created_by"`
+	Payload   json.RawMessage `json:"payload"`
+}
+
+// InviteePayload is the payload of invitee.created and invitee.canceled webhooks.
+type InviteePayload struct {
+	ID             string               `json:"uri"`
+	Email          string               `json:"email"`
+	Name           string               `json:"name"`
+	Status         string               `json:"status"`
+	Event          string               `json:"event"`
+	ScheduledEvent *ScheduledEvent      `json:"scheduled_event"`
+	Cancellation   *InviteeCancellation `json:"cancellation"`
+	Tracking       map[string]any       `json:"tracking"`
+	Questions      []QuestionAndAnswer  `json:"questions_and_answers"`
+	Rescheduled    bool                 `json:"rescheduled"`
+	CreatedAt      string               `json:"created_at"`
+	UpdatedAt      string               `json:"updated_at"`
+}
+
+type InviteeCancellation struct {
+	CanceledBy   string `json:"canceled_by"`
+	Reason       string `json:"reason"`
+	CancelerType string `json:"canceler_type"`
+	CreatedAt    string `json:"created_at"`
+}
+
+type QuestionAndAnswer struct {
+	Question string `json:"question"`
+	Answer   string `json:"answer"`
+	Position int    `json:"position"`
+}
+
+// RoutingFormSubmissionPayload is the payload of routing_form_submission.created webhooks.
+type RoutingFormSubmissionPayload struct {
+	ID            string                       `json:"uri"`
+	RoutingForm   string                       `json:"routing_form"`
+	Questions     []QuestionAndAnswer          `json:"questions_and_answers"`
+	Submitter     string                       `json:"submitter"`
+	SubmitterType string                       `json:"submitter_type"`
+	Result        *RoutingFormSubmissionResult `json:"result"`
+	CreatedAt     string                       `json:"created_at"`
+	UpdatedAt     string                       `json:"updated_at"`
+}
+
+type RoutingFormSubmissionResult struct {
+	Type  string `json:"type"`
+	Value any    `json:"value"`
+}
+
+// ParseWebhookEvent decodes the webhook envelope.
+func ParseWebhookEvent(body []byte) (*WebhookEvent, error) {
+	var rv WebhookEvent
+	if err := json.Unmarshal(body, &rv); err != nil {
+		return nil, fmt.Errorf("failed to parse webhook event: %w", err)
+	}
+
+	if rv.Event == "" {
+		return nil, fmt.Errorf("failed to parse webhook event: missing event name")
+	}
+
+	return &rv, nil
+}
+
+// OccurredAt returns the creation time of the webhook event, zero time if it can't be parsed.
+func (e *WebhookEvent) OccurredAt() time.Time {
+	t, err := time.Parse(time.RFC3339, e.CreatedAt)
+	if err != nil {
+		return time.Time{}
+	}
+
+	return t
+}
+
+// Invitee decodes payload of the invitee.created and invitee.canceled events.
+func (e *WebhookEvent) Invitee() (*InviteePayload, error) {
+	if e.Event != WebhookInviteeCreated && e.Event != WebhookInviteeCanceled {
+		return nil, fmt.Errorf("webhook event %s doesn't carry an invitee", e.Event)
+	}
+
+	var rv InviteePayload
+	if err := json.Unmarshal(e.Payload, &rv); err != nil {
+		return nil, fmt.Errorf("failed to parse invitee payload: %w", err)
+	}
+
+	return &rv, nil
+}
+
+// RoutingFormSubmission decodes payload of the routing_form_submission.created event.
+func (e *WebhookEvent) RoutingFormSubmission() (*RoutingFormSubmissionPayload, error) {
+	if e.Event != WebhookRoutingFormSubmission {
+		return nil, fmt.Errorf("webhook event %s doesn't carry a routing form submission", e.Event)
+	}
+
+	var rv RoutingFormSubmissionPayload
+	if err := json.Unmarshal(e.Payload, &rv); err != nil {
+		return nil, fmt.Errorf("failed to parse routing form submission payload: %w", err)
+	}
+
+	return &rv, nil
+}
@@ -0,0 +1,118 @@
+package calendly
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func sign(t *testing.T, signingKey string, ts int64, body string) string {
+	t.Helper()
+
+	mac := hmac.New(sha256.New, []byte(signingKey))
+	mac.Write([]byte(strconv.FormatInt(ts, 10) + "." + body))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerifyWebhookSignature(t *testing.T) {
+	const (
+		key  = "signing-key"
+		body = `{"event":"invitee.created","payload":{}}`
+	)
+
+	now := time.Unix(1700000000, 0)
+	ts := now.Unix()
+	valid := sign(t, key, ts, body)
+
+	tests := []struct {
+		name      string
+		header    string
+		body      string
+		tolerance time.Duration
+		want      error
+	}{
+		{
+			name:      "valid",
+			header:    fmt.Sprintf("t=%d,v1=%s", ts, valid),
+			body:      body,
+			tolerance: 3 * time.Minute,
+		},
+		{
+			name:      "valid with spaces and unknown parts",
+			header:    fmt.Sprintf("t=%d, v0=abc, v1=%s", ts, valid),
+			body:      body,
+			tolerance: 3 * time.Minute,
+		},
+		{
+			name:   "missing header",
+			header: "",
+			body:   body,
+			want:   ErrWebhookSignatureMissing,
+		},
+		{
+			name:   "missing signature",
+			header: fmt.Sprintf("t=%d", ts),
+			body:   body,
+			want:   ErrWebhookSignatureMissing,
+		},
+		{
+			name:   "missing timestamp",
+			header: "v1=" + valid,
+			body:   body,
+			want:   ErrWebhookSignatureMissing,
+		},
+		{
+			name:   "tampered body",
+			header: fmt.Sprintf("t=%d,v1=%s", ts, valid),
+			body:   body + " ",
+			want:   ErrWebhookSignatureInvalid,
+		},
+		{
+			name:   "wrong key",
+			header: fmt.Sprintf("t=%d,v1=%s", ts, sign(t, "other-key", ts, body)),
+			body:   body,
+			want:   ErrWebhookSignatureInvalid,
+		},
+		{
+			name:   "signature of another timestamp",
+			header: fmt.Sprintf("t=%d,v1=%s", ts+1, valid),
+			body:   body,
+			want:   ErrWebhookSignatureInvalid,
+		},
+		{
+			name:      "too old",
+			header:    fmt.Sprintf("t=%d,v1=%s", ts-600, sign(t, key, ts-600, body)),
+			body:      body,
+			tolerance: 3 * time.Minute,
+			want:      ErrWebhookSignatureExpired,
+		},
+		{
+			name:      "from the future",
+			header:    fmt.Sprintf("t=%d,v1=%s", ts+600, sign(t, key, ts+600, body)),
+			body:      body,
+			tolerance: 3 * time.Minute,
+			want:      ErrWebhookSignatureExpired,
+		},
+		{
+			name:      "old without tolerance",
+			header:    fmt.Sprintf("t=%d,v1=%s", ts-600, sign(t, key, ts-600, body)),
+			body:      body,
+			tolerance: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifyWebhookSignature(tt.header, []byte(tt.body), key, tt.tolerance, now)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("VerifyWebhookSignature() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/conductorone/baton-calendly/pkg/calendly"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultCancellationReason is the reason template used when none is configured.
+const DefaultCancellationReason = "{{.HostName}} is no longer available. We apologize for the inconvenience, please book a new time."
+
+// CancellationResult describes a single upcoming meeting of a departing member and the outcome of its cancellation.
+type CancellationResult struct {
+	EventURI  string `json:"event_uri"`
+	EventName string `json:"event_name"`
+	StartTime string `json:"start_time"`
+	Reason    string `json:"reason"`
+	Canceled  bool   `json:"canceled"`
+	Error     string `json:"error,omitempty"`
+}
+
+// cancellationReasonData are the fields available in the cancellation reason template.
+type cancellationReasonData struct {
+	HostName  string
+	HostEmail string
+	EventName string
+	StartTime string
+}
+
+// cancellationReason returns the configured reason template, parsed.
+func (c *Config) cancellationReason() (*template.Template, error) {
+	reason := DefaultCancellationReason
+	if c != nil && c.CancellationReason != "" {
+		reason = c.CancellationReason
+	}
+
+	t, err := template.New("reason").Option("missingkey=error").Parse(reason)
+	if err != nil {
+		return nil, fmt.Errorf("calendly-connector: invalid cancellation reason template: %w", err)
+	}
+
+	return t, nil
+}
+
+// cancelMeetingsOnOffboarding reports whether upcoming meetings of removed members are canceled.
+func (c *Config) cancelMeetingsOnOffboarding() bool {
+	return c != nil && c.CancelMeetingsOnOffboarding
+}
+
+// CancelUpcomingMeetings cancels every upcoming meeting hosted by the organization member with the email.
+// Without confirm only the report of meetings that would be canceled is produced.
+func (c *Calendly) CancelUpcomingMeetings(ctx context.Context, email string, confirm bool) ([]*CancellationResult, error) {
+	u, _, err := c.client.GetCurrentUser(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("calendly-connector: failed to get current user details: %w", err)
+	}
+
+	memberships, _, err := c.client.ListUsersUnderOrg(ctx, u.OrgURI, nil, calendly.NewFilterVars(email))
+	if err != nil {
+		return nil, fmt.Errorf("calendly-connector: failed to list users in org: %w", err)
+	}
+
+	if len(memberships) == 0 || memberships[0].User == nil {
+		return nil, status.Errorf(codes.NotFound, "calendly-connector: %s is not a member of the organization", email)
+	}
+
+	return cancelUpcomingMeetings(ctx, c.client, c.config, u.OrgURI, memberships[0].User, confirm)
+}
+
+// cancelUpcomingMeetings cancels active meetings of the user starting in the future with the configured reason.
+// Meetings are collected first, as canceled ones drop out of the listing while paging.
+func cancelUpcomingMeetings(
+	ctx context.Context,
+	client *calendly.Client,
+	config *Config,
+	orgURI string,
+	user *calendly.User,
+	confirm bool,
+) ([]*CancellationResult, error) {
+	l := ctxzap.Extract(ctx)
+
+	reason, err := config.cancellationReason()
+	if err != nil {
+		return nil, err
+	}
+
+	filter := &calendly.EventFilterVars{
+		UserURI:      user.ID,
+		Status:       calendly.ScheduledEventStatusActive,
+		MinStartTime: time.Now(),
+		Sort:         "start_time:asc",
+	}
+
+	var events []calendly.ScheduledEvent
+	page := ""
+	for {
+		e, nextPage, _, err := client.ListScheduledEvents(ctx, orgURI, calendly.NewPaginationVars(config.pageSize(client), page), filter)
+		if err != nil {
+			return nil, fmt.Errorf("calendly-connector: failed to list upcoming scheduled events: %w", err)
+		}
+
+		events = append(events, e...)
+		if nextPage == "" {
+			break
+		}
+		page = nextPage
+	}
+
+	rv := make([]*CancellationResult, 0, len(events))
+	for _, e := range events {
+		var b strings.Builder
+		err := reason.Execute(&b, &cancellationReasonData{
+			HostName:  user.FullName,
+			HostEmail: user.Email,
+			EventName: e.Name,
+			StartTime: e.StartTime,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("calendly-connector: failed to render cancellation reason: %w", err)
+		}
+
+		rv = append(rv, &CancellationResult{
+			EventURI:  e.ID,
+			EventName: e.Name,
+			StartTime: e.StartTime,
+			Reason:    b.String(),
+		})
+	}
+
+	if !confirm {
+		l.Info("calendly-connector: dry run, no meetings canceled", zap.String("email", user.Email), zap.Int("upcoming_events", len(rv)))
+		return rv, nil
+	}
+
+	for _, r := range rv {
+		_, err := client.CancelScheduledEvent(ctx, r.EventURI, r.Reason)
+		if err != nil {
+			r.Error = err.Error()
+			l.Error(
+				"calendly-connector: failed to cancel meeting",
+				zap.String("email", user.Email),
+				zap.String("event_uri", r.EventURI),
+				zap.String("start_time", r.StartTime),
+				zap.Error(err),
+			)
+
+			continue
+		}
+
+		r.Canceled = true
+		l.Info(
+			"calendly-connector: canceled meeting",
+			zap.String("email", user.Email),
+			zap.String("event_uri", r.EventURI),
+			zap.String("start_time", r.StartTime),
+		)
+	}
+
+	return rv, nil
+}
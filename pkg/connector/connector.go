@@ -2,27 +2,88 @@ package connector
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"net/http"
+	"slices"
+	"time"
 
 	"github.com/conductorone/baton-calendly/pkg/calendly"
+	"github.com/conductorone/baton-calendly/pkg/scim"
 	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
 	"github.com/conductorone/baton-sdk/pkg/annotations"
 	"github.com/conductorone/baton-sdk/pkg/connectorbuilder"
 	"github.com/conductorone/baton-sdk/pkg/uhttp"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
 )
 
 type Calendly struct {
 	client *calendly.Client
+	scim   *scim.Client
+	config *Config
+}
+
+// Config holds optional connector settings. The zero value keeps the default behavior.
+type Config struct {
+	// ActivityLookback is how far back scheduled events are searched to find the last activity of a user.
+	// Zero disables the lookup.
+	ActivityLookback time.Duration
+
+	// IncludeEmailDomains limits synced users to the email domains. Empty list includes all domains.
+	IncludeEmailDomains []string
+	// ExcludeEmailDomains skips users with the email domains.
+	ExcludeEmailDomains []string
+	// Roles limits synced members to the organization roles. Empty list includes all roles.
+	Roles []string
+	// SkipPendingInvitations skips users with pending invitation to the organization.
+	SkipPendingInvitations bool
+	// InvitationHistory records accepted and declined invitations of members in the metadata of their grants.
+	InvitationHistory bool
+
+	// PageSize is the number of items requested per page, capped at the Calendly maximum. Zero uses the default.
+	PageSize int
+	// AdaptivePageSize grows the page size up to the Calendly maximum while there is rate limit headroom,
+	// shrinks it back and slows down requests as the headroom runs out.
+	AdaptivePageSize bool
+
+	// LookupCacheTTL is how long lookups of the current user and organizations are cached. Zero disables the cache.
+	LookupCacheTTL time.Duration
+
+	// IncrementalSync emits only user role grants changed since the last full sync, reusing the rest from the previous sync.
+	IncrementalSync bool
+	// FullSyncInterval is how often all grants are emitted in incremental mode, even when no member changed.
+	FullSyncInterval time.Duration
+
+	// SCIMBaseURL and SCIMToken enable SCIM provisioning for Enterprise organizations. Both are required.
+	// With SCIM, accounts are created, deactivated and reactivated directly instead of through invitations.
+	SCIMBaseURL string
+	SCIMToken   string
+
+	// ForceOffboarding removes members even when they host upcoming meetings or own event types.
+	ForceOffboarding bool
+	// CancelMeetingsOnOffboarding cancels upcoming meetings hosted by a member before the member is removed.
+	CancelMeetingsOnOffboarding bool
+	// CancellationReason is the text/template of the reason sent to invitees of canceled meetings.
+	// Empty uses DefaultCancellationReason.
+	CancellationReason string
+
+	// WebhookEventsFile is the JSON lines file where received webhooks are buffered for the event feed.
+	WebhookEventsFile string
 }
 
 // ResourceSyncers returns a ResourceSyncer for each resource type that should be synced from the upstream service.
 func (c *Calendly) ResourceSyncers(ctx context.Context) []connectorbuilder.ResourceSyncer {
+	snapshots := newSnapshotStore(c.client, c.scim, c.config)
+
 	return []connectorbuilder.ResourceSyncer{
-		newOrgBuilder(c.client),
-		newUserBuilder(c.client),
+		newOrgBuilder(c.client, c.scim, c.config, snapshots),
+		newUserBuilder(c.client, c.scim, c.config, snapshots),
+		newGroupBuilder(c.client, c.config),
+		newEventTypeBuilder(c.client, c.config, snapshots),
+		newRoutingFormBuilder(c.client, c.config),
+		newWebhookSubscriptionBuilder(c.client, c.config),
 	}
 }
 
@@ -41,23 +102,73 @@ func (c *Calendly) Metadata(ctx context.Context) (*v2.ConnectorMetadata, error)
 }
 
 // Validate is called to ensure that the connector is properly configured. It should exercise any API credentials
-// to be sure that they are valid.
+// to be sure that they are valid. The token owner must be an admin or owner of the organization.
+// Returned annotations describe optional features available for the organization's plan.
 func (c *Calendly) Validate(ctx context.Context) (annotations.Annotations, error) {
 	u, _, err := c.client.GetCurrentUser(ctx)
 	if err != nil {
 		return nil, status.Error(codes.Unauthenticated, "calendly-connector: failed to validate credentials")
 	}
 
-	_, _, err = c.client.GetOrgDetails(ctx, u.OrgURI)
+	org, _, err := c.client.GetOrgDetails(ctx, u.OrgURI)
 	if err != nil {
 		return nil, status.Error(codes.Unauthenticated, "calendly-connector: failed to validate credentials")
 	}
 
-	return nil, nil
+	memberships, _, err := c.client.ListUsersUnderOrg(ctx, u.OrgURI, nil, calendly.NewUserFilterVars(u.ID))
+	if err != nil {
+		return nil, fmt.Errorf("calendly-connector: failed to get token owner membership: %w", err)
+	}
+
+	if len(memberships) == 0 {
+		return nil, status.Error(codes.PermissionDenied, "calendly-connector: token owner is not a member of the organization")
+	}
+
+	role := memberships[0].Role
+	if role != OrgAdminEntitlement && role != OrgOwnerEntitlement {
+		return nil, status.Errorf(codes.PermissionDenied, "calendly-connector: token owner has %s role, admin or owner role is required", role)
+	}
+
+	features, err := structpb.NewStruct(planFeatures(org.Plan))
+	if err != nil {
+		return nil, fmt.Errorf("calendly-connector: failed to create plan features annotation: %w", err)
+	}
+
+	annos := annotations.Annotations{}
+	annos.Append(features)
+
+	return annos, nil
+}
+
+// webhookPlans lists the plans that include webhook subscriptions, Basic and Essentials don't.
+var webhookPlans = []string{
+	calendly.PlanStandard,
+	calendly.PlanProfessional,
+	calendly.PlanTeams,
+	calendly.PlanEnterprise,
+}
+
+// planFeatures returns which optional Calendly features are available for the organization plan.
+// Groups and activity log are Enterprise only, webhooks require the Standard plan or higher.
+func planFeatures(plan string) map[string]interface{} {
+	return map[string]interface{}{
+		"plan":         plan,
+		"groups":       plan == calendly.PlanEnterprise,
+		"activity_log": plan == calendly.PlanEnterprise,
+		"webhooks":     slices.Contains(webhookPlans, plan),
+	}
 }
 
 // New returns a new instance of the connector.
-func New(ctx context.Context, token string) (*Calendly, error) {
+func New(ctx context.Context, token string, config *Config) (*Calendly, error) {
+	if config != nil {
+		for _, role := range config.Roles {
+			if !slices.Contains(OrgRoles, role) {
+				return nil, fmt.Errorf("calendly-connector: unknown role filter %s", role)
+			}
+		}
+	}
+
 	var (
 		httpClient *http.Client
 		err        error
@@ -74,7 +185,31 @@ func New(ctx context.Context, token string) (*Calendly, error) {
 		}
 	}
 
+	var cacheTTL time.Duration
+	if config != nil {
+		cacheTTL = config.LookupCacheTTL
+	}
+
+	client, err := calendly.NewClient(ctx, httpClient, cacheTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	var scimClient *scim.Client
+	if config != nil && (config.SCIMBaseURL != "" || config.SCIMToken != "") {
+		if config.SCIMBaseURL == "" || config.SCIMToken == "" {
+			return nil, fmt.Errorf("calendly-connector: both SCIM base URL and SCIM token are required to enable SCIM")
+		}
+
+		scimClient, err = scim.NewClient(ctx, config.SCIMBaseURL, config.SCIMToken)
+		if err != nil {
+			return nil, fmt.Errorf("calendly-connector: failed to create SCIM client: %w", err)
+		}
+	}
+
 	return &Calendly{
-		client: calendly.NewClient(httpClient),
+		client: client,
+		scim:   scimClient,
+		config: config,
 	}, nil
 }
@@ -0,0 +1,159 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/conductorone/baton-calendly/pkg/calendly"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+)
+
+const (
+	DataComplianceInviteeDeletion = "invitee_data_deletion"
+	DataComplianceEventDeletion   = "event_data_deletion"
+
+	DataComplianceStatusAccepted = "accepted"
+	DataComplianceStatusFailed   = "failed"
+	DataComplianceStatusDryRun   = "dry_run"
+)
+
+// DataComplianceRecord is an auditable record of a data compliance request submitted to Calendly.
+type DataComplianceRecord struct {
+	Type        string     `json:"type"`
+	RequestedAt time.Time  `json:"requested_at"`
+	Emails      []string   `json:"emails,omitempty"`
+	StartTime   *time.Time `json:"start_time,omitempty"`
+	EndTime     *time.Time `json:"end_time,omitempty"`
+	EventCount  *int       `json:"event_count,omitempty"`
+	Status      string     `json:"status"`
+	Error       string     `json:"error,omitempty"`
+}
+
+// DeleteInviteeData submits deletion of invitee data for the emails. Emails are sent in batches
+// of the maximum size allowed by Calendly and one record is returned for each batch.
+func (c *Calendly) DeleteInviteeData(ctx context.Context, emails []string) ([]*DataComplianceRecord, error) {
+	l := ctxzap.Extract(ctx)
+
+	var cleaned []string
+	for _, email := range emails {
+		email = strings.TrimSpace(email)
+		if email == "" {
+			continue
+		}
+
+		if !strings.Contains(email, "@") {
+			return nil, fmt.Errorf("calendly-connector: invalid email: %s", email)
+		}
+
+		cleaned = append(cleaned, email)
+	}
+
+	if len(cleaned) == 0 {
+		return nil, fmt.Errorf("calendly-connector: at least one email is required")
+	}
+
+	var rv []*DataComplianceRecord
+	for start := 0; start < len(cleaned); start += calendly.MaxInviteeDataDeletionEmails {
+		batch := cleaned[start:min(start+calendly.MaxInviteeDataDeletionEmails, len(cleaned))]
+
+		record := &DataComplianceRecord{
+			Type:        DataComplianceInviteeDeletion,
+			RequestedAt: time.Now().UTC(),
+			Emails:      batch,
+			Status:      DataComplianceStatusAccepted,
+		}
+
+		_, err := c.client.DeleteInviteeData(ctx, batch)
+		if err != nil {
+			record.Status = DataComplianceStatusFailed
+			record.Error = err.Error()
+			l.Error("calendly-connector: invitee data deletion failed", zap.Int("emails", len(batch)), zap.Error(err))
+		} else {
+			l.Info("calendly-connector: invitee data deletion accepted", zap.Int("emails", len(batch)))
+		}
+
+		rv = append(rv, record)
+	}
+
+	return rv, nil
+}
+
+// DeleteEventData submits deletion of scheduled event data within the time window. The window can't be longer
+// than the maximum allowed by Calendly. With dry run only the number of affected scheduled events is counted.
+func (c *Calendly) DeleteEventData(ctx context.Context, start, end time.Time, dryRun bool) (*DataComplianceRecord, error) {
+	l := ctxzap.Extract(ctx)
+
+	if !start.Before(end) {
+		return nil, fmt.Errorf("calendly-connector: start time must be before end time")
+	}
+
+	if end.Sub(start) > calendly.MaxEventDataDeletionRange {
+		return nil, fmt.Errorf("calendly-connector: time window can't be longer than %s", calendly.MaxEventDataDeletionRange)
+	}
+
+	record := &DataComplianceRecord{
+		Type:        DataComplianceEventDeletion,
+		RequestedAt: time.Now().UTC(),
+		StartTime:   &start,
+		EndTime:     &end,
+		Status:      DataComplianceStatusAccepted,
+	}
+
+	if dryRun {
+		count, err := c.countScheduledEvents(ctx, start, end)
+		if err != nil {
+			return nil, err
+		}
+
+		record.EventCount = &count
+		record.Status = DataComplianceStatusDryRun
+	} else {
+		_, err := c.client.DeleteEventData(ctx, start, end)
+		if err != nil {
+			record.Status = DataComplianceStatusFailed
+			record.Error = err.Error()
+		}
+	}
+
+	l.Info(
+		"calendly-connector: scheduled event data deletion",
+		zap.String("status", record.Status),
+		zap.Time("start_time", start),
+		zap.Time("end_time", end),
+		zap.Intp("event_count", record.EventCount),
+		zap.String("error", record.Error),
+	)
+
+	return record, nil
+}
+
+// countScheduledEvents returns number of the organization's scheduled events starting within the time window.
+func (c *Calendly) countScheduledEvents(ctx context.Context, start, end time.Time) (int, error) {
+	u, _, err := c.client.GetCurrentUser(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("calendly-connector: failed to get current user details: %w", err)
+	}
+
+	filter := &calendly.EventFilterVars{
+		MinStartTime: start,
+		MaxStartTime: end,
+	}
+
+	count := 0
+	page := ""
+	for {
+		events, nextPage, _, err := c.client.ListScheduledEvents(ctx, u.OrgURI, calendly.NewPaginationVars(c.config.pageSize(c.client), page), filter)
+		if err != nil {
+			return 0, fmt.Errorf("calendly-connector: failed to list scheduled events: %w", err)
+		}
+
+		count += len(events)
+		if nextPage == "" {
+			return count, nil
+		}
+		page = nextPage
+	}
+}
@@ -0,0 +1,175 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/conductorone/baton-calendly/pkg/calendly"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	ent "github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/conductorone/baton-sdk/pkg/types/grant"
+	rs "github.com/conductorone/baton-sdk/pkg/types/resource"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const EventTypeHostEntitlement = "host"
+
+var errHostAssignmentUnsupported = status.Error(codes.Unimplemented, "calendly-connector: event type host assignment is not supported by the Calendly API")
+
+type eventTypeBuilder struct {
+	client       *calendly.Client
+	resourceType *v2.ResourceType
+	config       *Config
+	snapshots    *snapshotStore
+}
+
+func (e *eventTypeBuilder) ResourceType(ctx context.Context) *v2.ResourceType {
+	return eventTypeResourceType
+}
+
+func eventTypeResource(eventType *calendly.EventType, parentID *v2.ResourceId) (*v2.Resource, error) {
+	kind := eventType.Kind
+	if eventType.PoolingType != "" {
+		kind = eventType.PoolingType
+	}
+
+	if eventType.AdminManaged {
+		kind = "managed " + kind
+	}
+
+	resource, err := rs.NewResource(
+		eventType.Name,
+		eventTypeResourceType,
+		eventType.ID,
+		rs.WithParentResourceID(parentID),
+		rs.WithDescription(fmt.Sprintf("%s event type, %d minutes", kind, eventType.Duration)),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return resource, nil
+}
+
+// List returns all event types under the organization.
+func (e *eventTypeBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId, pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	if parentResourceID == nil {
+		return nil, "", nil, nil
+	}
+
+	bag, page, err := parsePageToken(pToken.Token, &v2.ResourceId{ResourceType: eventTypeResourceType.Id})
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	if err := e.config.waitForRateLimit(ctx, e.client); err != nil {
+		return nil, "", nil, err
+	}
+
+	pgVars := calendly.NewPaginationVars(e.config.pageSize(e.client), page)
+	eventTypes, nextPage, rle, err := e.client.ListEventTypes(ctx, parentResourceID.Resource, pgVars)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("calendly-connector: failed to list event types: %w", err)
+	}
+
+	var rv []*v2.Resource
+	for _, et := range eventTypes {
+		er, err := eventTypeResource(&et, parentResourceID)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("calendly-connector: failed to create event type resource: %w", err)
+		}
+
+		rv = append(rv, er)
+	}
+
+	next, err := bag.NextToken(nextPage)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	return rv, next, WithRateLimitAnnotations(rle), nil
+}
+
+// Entitlements returns the host entitlement for the event type.
+func (e *eventTypeBuilder) Entitlements(ctx context.Context, resource *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	hostOptions := []ent.EntitlementOption{
+		ent.WithGrantableTo(userResourceType),
+		ent.WithDisplayName(fmt.Sprintf("%s host", resource.DisplayName)),
+		ent.WithDescription(fmt.Sprintf("Host of %s event type", resource.DisplayName)),
+	}
+
+	return []*v2.Entitlement{
+		ent.NewAssignmentEntitlement(resource, EventTypeHostEntitlement, hostOptions...),
+	}, "", nil, nil
+}
+
+// Grants returns host grants for the event type. Hosts skipped by the user filters are left out,
+// like their user resources.
+func (e *eventTypeBuilder) Grants(ctx context.Context, resource *v2.Resource, pToken *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	if resource.ParentResourceId == nil {
+		return nil, "", nil, nil
+	}
+
+	snapshot, err := e.snapshots.get(ctx, resource.ParentResourceId.Resource)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	bag, page, err := parsePageToken(pToken.Token, resource.Id)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("calendly-connector: failed to parse page token: %w", err)
+	}
+
+	if err := e.config.waitForRateLimit(ctx, e.client); err != nil {
+		return nil, "", nil, err
+	}
+
+	pgVars := calendly.NewPaginationVars(e.config.pageSize(e.client), page)
+	hosts, nextPage, rlh, err := e.client.ListEventTypeHosts(ctx, resource.Id.Resource, pgVars)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("calendly-connector: failed to list event type hosts: %w", err)
+	}
+
+	var rv []*v2.Grant
+	for _, h := range hosts {
+		if !e.config.includeHost(snapshot, h.Member) {
+			continue
+		}
+
+		userId, err := rs.NewResourceID(userResourceType, h.Member.ID)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("calendly-connector: failed to create user resource id: %w", err)
+		}
+
+		rv = append(rv, grant.NewGrant(resource, EventTypeHostEntitlement, userId))
+	}
+
+	next, err := bag.NextToken(nextPage)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	return rv, next, WithRateLimitAnnotations(rlh), nil
+}
+
+// Grant refuses to assign hosts, the Calendly API doesn't expose host assignment of event types.
+func (e *eventTypeBuilder) Grant(_ context.Context, _ *v2.Resource, _ *v2.Entitlement) (annotations.Annotations, error) {
+	return nil, errHostAssignmentUnsupported
+}
+
+// Revoke refuses to remove hosts, the Calendly API doesn't expose host assignment of event types.
+func (e *eventTypeBuilder) Revoke(_ context.Context, _ *v2.Grant) (annotations.Annotations, error) {
+	return nil, errHostAssignmentUnsupported
+}
+
+func newEventTypeBuilder(client *calendly.Client, config *Config, snapshots *snapshotStore) *eventTypeBuilder {
+	return &eventTypeBuilder{
+		client:       client,
+		resourceType: eventTypeResourceType,
+		config:       config,
+		snapshots:    snapshots,
+	}
+}
@@ -0,0 +1,72 @@
+package connector
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/conductorone/baton-calendly/pkg/calendly"
+)
+
+// includeEmail reports whether user with the email passes the configured email domain filters.
+func (c *Config) includeEmail(email string) bool {
+	if c == nil {
+		return true
+	}
+
+	domain := ""
+	if i := strings.LastIndex(email, "@"); i != -1 {
+		domain = strings.ToLower(email[i+1:])
+	}
+
+	if len(c.IncludeEmailDomains) > 0 && !containsDomain(c.IncludeEmailDomains, domain) {
+		return false
+	}
+
+	return !containsDomain(c.ExcludeEmailDomains, domain)
+}
+
+// includeMembership reports whether the organization member passes the configured filters.
+func (c *Config) includeMembership(m *calendly.OrgMembership) bool {
+	if c == nil {
+		return true
+	}
+
+	if len(c.Roles) > 0 && !slices.Contains(c.Roles, m.Role) {
+		return false
+	}
+
+	return m.User != nil && c.includeEmail(m.User.Email)
+}
+
+// includeHost reports whether the event type host is a member of the organization passing the configured filters,
+// as only those members have user resources.
+func (c *Config) includeHost(snapshot *orgSnapshot, host *calendly.User) bool {
+	if host == nil {
+		return false
+	}
+
+	m := snapshot.membership(host.ID)
+
+	return m != nil && c.includeMembership(m)
+}
+
+// invitationHistory reports whether accepted and declined invitations are fetched for grant metadata.
+func (c *Config) invitationHistory() bool {
+	return c != nil && c.InvitationHistory
+}
+
+// includeInvitations reports whether pending invitations are synced at all.
+func (c *Config) includeInvitations() bool {
+	return c == nil || !c.SkipPendingInvitations
+}
+
+// includeInvitation reports whether the pending invitation passes the configured filters.
+func (c *Config) includeInvitation(i *calendly.Invitation) bool {
+	return c.includeInvitations() && c.includeEmail(i.Email)
+}
+
+func containsDomain(domains []string, domain string) bool {
+	return slices.ContainsFunc(domains, func(d string) bool {
+		return strings.EqualFold(strings.TrimPrefix(strings.TrimSpace(d), "@"), domain)
+	})
+}
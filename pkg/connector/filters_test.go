@@ -0,0 +1,117 @@
+package connector
+
+import (
+	"testing"
+
+	"github.com/conductorone/baton-calendly/pkg/calendly"
+)
+
+func TestIncludeEmail(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *Config
+		email  string
+		want   bool
+	}{
+		{name: "no config", config: nil, email: "a@example.com", want: true},
+		{name: "no filters", config: &Config{}, email: "a@example.com", want: true},
+		{name: "included domain", config: &Config{IncludeEmailDomains: []string{"example.com"}}, email: "a@example.com", want: true},
+		{name: "included domain with @ and case", config: &Config{IncludeEmailDomains: []string{" @Example.COM "}}, email: "a@EXAMPLE.com", want: true},
+		{name: "other domain", config: &Config{IncludeEmailDomains: []string{"example.com"}}, email: "a@other.com", want: false},
+		{name: "subdomain is another domain", config: &Config{IncludeEmailDomains: []string{"example.com"}}, email: "a@sub.example.com", want: false},
+		{name: "excluded domain", config: &Config{ExcludeEmailDomains: []string{"contractor.com"}}, email: "a@contractor.com", want: false},
+		{name: "not excluded domain", config: &Config{ExcludeEmailDomains: []string{"contractor.com"}}, email: "a@example.com", want: true},
+		{
+			name:   "exclude wins over include",
+			config: &Config{IncludeEmailDomains: []string{"example.com"}, ExcludeEmailDomains: []string{"example.com"}},
+			email:  "a@example.com",
+			want:   false,
+		},
+		{name: "missing domain with include filter", config: &Config{IncludeEmailDomains: []string{"example.com"}}, email: "a", want: false},
+		{name: "missing domain without include filter", config: &Config{ExcludeEmailDomains: []string{"example.com"}}, email: "a", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.config.includeEmail(tt.email); got != tt.want {
+				t.Errorf("includeEmail(%q) = %v, want %v", tt.email, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIncludeMembership(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *Config
+		m      calendly.OrgMembership
+		want   bool
+	}{
+		{name: "no config", config: nil, m: testMembership("a", OrgUserEntitlement, ""), want: true},
+		{name: "role filter match", config: &Config{Roles: []string{OrgAdminEntitlement}}, m: testMembership("a", OrgAdminEntitlement, ""), want: true},
+		{name: "role filter miss", config: &Config{Roles: []string{OrgAdminEntitlement}}, m: testMembership("a", OrgUserEntitlement, ""), want: false},
+		{name: "excluded email", config: &Config{ExcludeEmailDomains: []string{"example.com"}}, m: testMembership("a", OrgUserEntitlement, ""), want: false},
+		{name: "missing user", config: &Config{}, m: calendly.OrgMembership{Role: OrgUserEntitlement}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.config.includeMembership(&tt.m); got != tt.want {
+				t.Errorf("includeMembership() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIncludeHost(t *testing.T) {
+	snapshot := &orgSnapshot{
+		memberships: []calendly.OrgMembership{
+			testMembership("admin", OrgAdminEntitlement, ""),
+			testMembership("user", OrgUserEntitlement, ""),
+		},
+	}
+
+	tests := []struct {
+		name   string
+		config *Config
+		host   *calendly.User
+		want   bool
+	}{
+		{name: "member", config: &Config{}, host: testMembership("user", OrgUserEntitlement, "").User, want: true},
+		{name: "role filter match", config: &Config{Roles: []string{OrgAdminEntitlement}}, host: testMembership("admin", OrgAdminEntitlement, "").User, want: true},
+		{name: "role filter miss", config: &Config{Roles: []string{OrgAdminEntitlement}}, host: testMembership("user", OrgUserEntitlement, "").User, want: false},
+		{name: "excluded email", config: &Config{ExcludeEmailDomains: []string{"example.com"}}, host: testMembership("user", OrgUserEntitlement, "").User, want: false},
+		{name: "not a member", config: &Config{}, host: testMembership("other", OrgUserEntitlement, "").User, want: false},
+		{name: "missing host", config: &Config{}, host: nil, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.config.includeHost(snapshot, tt.host); got != tt.want {
+				t.Errorf("includeHost() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIncludeInvitation(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *Config
+		email  string
+		want   bool
+	}{
+		{name: "no config", config: nil, email: "a@example.com", want: true},
+		{name: "invitations synced", config: &Config{}, email: "a@example.com", want: true},
+		{name: "invitations skipped", config: &Config{SkipPendingInvitations: true}, email: "a@example.com", want: false},
+		{name: "excluded email", config: &Config{ExcludeEmailDomains: []string{"example.com"}}, email: "a@example.com", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.config.includeInvitation(&calendly.Invitation{Email: tt.email}); got != tt.want {
+				t.Errorf("includeInvitation(%q) = %v, want %v", tt.email, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/conductorone/baton-calendly/pkg/calendly"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	ent "github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/conductorone/baton-sdk/pkg/types/grant"
+	rs "github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	GroupMemberEntitlement = calendly.GroupRoleMember
+	GroupAdminEntitlement  = calendly.GroupRoleAdmin
+)
+
+type groupBuilder struct {
+	client       *calendly.Client
+	resourceType *v2.ResourceType
+	config       *Config
+}
+
+func (g *groupBuilder) ResourceType(ctx context.Context) *v2.ResourceType {
+	return groupResourceType
+}
+
+func groupResource(group *calendly.Group, parentID *v2.ResourceId) (*v2.Resource, error) {
+	profile := map[string]interface{}{
+		"group_id":     group.ID,
+		"name":         group.Name,
+		"member_count": group.MemberCount,
+	}
+
+	resource, err := rs.NewGroupResource(
+		group.Name,
+		groupResourceType,
+		group.ID,
+		[]rs.GroupTraitOption{rs.WithGroupProfile(profile)},
+		rs.WithParentResourceID(parentID),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return resource, nil
+}
+
+// List returns all groups under the organization. Groups are available only on the Enterprise plan,
+// so the list is empty when the API denies access to them.
+func (g *groupBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId, pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	if parentResourceID == nil {
+		return nil, "", nil, nil
+	}
+
+	bag, page, err := parsePageToken(pToken.Token, &v2.ResourceId{ResourceType: groupResourceType.Id})
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	if err := g.config.waitForRateLimit(ctx, g.client); err != nil {
+		return nil, "", nil, err
+	}
+
+	pgVars := calendly.NewPaginationVars(g.config.pageSize(g.client), page)
+	groups, nextPage, rlg, err := g.client.ListGroups(ctx, parentResourceID.Resource, pgVars)
+	if err != nil {
+		if status.Code(err) == codes.PermissionDenied {
+			l.Debug("calendly-connector: groups are not available for the organization", zap.Error(err))
+			return nil, "", nil, nil
+		}
+
+		return nil, "", nil, fmt.Errorf("calendly-connector: failed to list groups: %w", err)
+	}
+
+	var rv []*v2.Resource
+	for _, group := range groups {
+		gr, err := groupResource(&group, parentResourceID)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("calendly-connector: failed to create group resource: %w", err)
+		}
+
+		rv = append(rv, gr)
+	}
+
+	next, err := bag.NextToken(nextPage)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	return rv, next, WithRateLimitAnnotations(rlg), nil
+}
+
+// Entitlements returns member and group admin entitlements for the group.
+func (g *groupBuilder) Entitlements(ctx context.Context, resource *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	memberOptions := []ent.EntitlementOption{
+		ent.WithGrantableTo(userResourceType),
+		ent.WithDisplayName(fmt.Sprintf("%s group member", resource.DisplayName)),
+		ent.WithDescription(fmt.Sprintf("Member of %s group", resource.DisplayName)),
+	}
+
+	adminOptions := []ent.EntitlementOption{
+		ent.WithGrantableTo(userResourceType),
+		ent.WithDisplayName(fmt.Sprintf("%s group admin", resource.DisplayName)),
+		ent.WithDescription(fmt.Sprintf("Admin managing %s group", resource.DisplayName)),
+	}
+
+	return []*v2.Entitlement{
+		ent.NewAssignmentEntitlement(resource, GroupMemberEntitlement, memberOptions...),
+		ent.NewPermissionEntitlement(resource, GroupAdminEntitlement, adminOptions...),
+	}, "", nil, nil
+}
+
+// Grants returns member and group admin grants for the group.
+func (g *groupBuilder) Grants(ctx context.Context, resource *v2.Resource, pToken *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	if resource.ParentResourceId == nil {
+		return nil, "", nil, nil
+	}
+
+	bag, page, err := parsePageToken(pToken.Token, resource.Id)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("calendly-connector: failed to parse page token: %w", err)
+	}
+
+	if err := g.config.waitForRateLimit(ctx, g.client); err != nil {
+		return nil, "", nil, err
+	}
+
+	pgVars := calendly.NewPaginationVars(g.config.pageSize(g.client), page)
+	relationships, nextPage, rlr, err := g.client.ListGroupRelationships(ctx, resource.ParentResourceId.Resource, resource.Id.Resource, pgVars)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("calendly-connector: failed to list group relationships: %w", err)
+	}
+
+	var rv []*v2.Grant
+	for _, r := range relationships {
+		if r.Owner == nil || (r.Role != GroupMemberEntitlement && r.Role != GroupAdminEntitlement) {
+			continue
+		}
+
+		// users skipped by the filters have no user resource to grant to
+		principal := groupRelationshipPrincipal(r.Owner)
+		if !g.config.includeEmail(r.Owner.Email) || (principal == r.Owner.Email && !g.config.includeInvitations()) {
+			continue
+		}
+
+		userId, err := rs.NewResourceID(userResourceType, principal)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("calendly-connector: failed to create user resource id: %w", err)
+		}
+
+		rv = append(rv, grant.NewGrant(resource, r.Role, userId))
+	}
+
+	next, err := bag.NextToken(nextPage)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	return rv, next, WithRateLimitAnnotations(rlr), nil
+}
+
+// groupRelationshipPrincipal returns ID of the user resource for the relationship owner.
+// Members are identified by their user URI, pending invitations by the email.
+func groupRelationshipPrincipal(owner *calendly.GroupRelationOwner) string {
+	if strings.Contains(owner.ID, "/users/") {
+		return owner.ID
+	}
+
+	return owner.Email
+}
+
+func newGroupBuilder(client *calendly.Client, config *Config) *groupBuilder {
+	return &groupBuilder{
+		client:       client,
+		resourceType: groupResourceType,
+		config:       config,
+	}
+}
@@ -1,15 +1,79 @@
 package connector
 
 import (
+	"context"
+	"fmt"
 	"strings"
+	"time"
 
+	"github.com/conductorone/baton-calendly/pkg/calendly"
 	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
 	"github.com/conductorone/baton-sdk/pkg/annotations"
 	"github.com/conductorone/baton-sdk/pkg/pagination"
+	rs "github.com/conductorone/baton-sdk/pkg/types/resource"
 )
 
 var ResourcesPageSize = 50
 
+// rateLimitReserve is the number of requests left in the rate limit window below which paging waits for the reset.
+const rateLimitReserve = 2
+
+// pageSize returns number of items requested per page, capped at the Calendly maximum. In adaptive mode
+// the page size grows from the configured one up to the maximum with the rate limit headroom reported by
+// the most recent response of the client, so fewer requests are made while the window has room.
+// A nil client, used for snapshots and SCIM, always gets the configured page size.
+func (c *Config) pageSize(client *calendly.Client) int {
+	size := ResourcesPageSize
+	if c != nil && c.PageSize > 0 {
+		size = min(c.PageSize, calendly.MaxPageSize)
+	}
+
+	if c == nil || !c.AdaptivePageSize || client == nil {
+		return size
+	}
+
+	rl := client.RateLimit()
+	if rl == nil || rl.Limit <= 0 {
+		return size
+	}
+
+	headroom := min(float64(rl.Remaining)/float64(rl.Limit), 1)
+
+	return size + int(float64(calendly.MaxPageSize-size)*max(headroom, 0))
+}
+
+// waitForRateLimit waits for the rate limit window to reset when the remaining requests drop to the reserve.
+// In adaptive mode, once less than half of the window is left, the remaining requests are spread evenly
+// over the rest of the window as well, while pages shrink back to the configured size.
+func (c *Config) waitForRateLimit(ctx context.Context, client *calendly.Client) error {
+	rl := client.RateLimit()
+	if rl == nil || rl.ResetAt == nil {
+		return nil
+	}
+
+	untilReset := time.Until(rl.ResetAt.AsTime())
+	if untilReset <= 0 {
+		return nil
+	}
+
+	var wait time.Duration
+	switch {
+	case rl.Remaining <= rateLimitReserve:
+		wait = untilReset
+	case c != nil && c.AdaptivePageSize && rl.Remaining < rl.Limit/2:
+		wait = untilReset / time.Duration(rl.Remaining)
+	default:
+		return nil
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(wait):
+		return nil
+	}
+}
+
 func annotationsForUserResourceType() annotations.Annotations {
 	annos := annotations.Annotations{}
 	annos.Update(&v2.SkipEntitlementsAndGrants{})
@@ -44,6 +108,36 @@ func parseResourceID(uri string) string {
 	return uri[index+1:]
 }
 
+// isURI reports whether the resource ID is a Calendly API URI rather than an email.
+func isURI(id string) bool {
+	return strings.HasPrefix(id, "https://")
+}
+
+// principalEmail returns the primary email of the user principal. Pending users are identified
+// directly by their email, other users carry it in the user trait.
+func principalEmail(principal *v2.Resource) string {
+	if strings.Contains(principal.Id.Resource, "@") && !isURI(principal.Id.Resource) {
+		return principal.Id.Resource
+	}
+
+	ut, err := rs.GetUserTrait(principal)
+	if err != nil {
+		return ""
+	}
+
+	for _, e := range ut.Emails {
+		if e.IsPrimary {
+			return e.Address
+		}
+	}
+
+	if len(ut.Emails) > 0 {
+		return ut.Emails[0].Address
+	}
+
+	return ""
+}
+
 func WithRateLimitAnnotations(rlDesc ...*v2.RateLimitDescription) annotations.Annotations {
 	annos := annotations.Annotations{}
 
@@ -53,3 +147,32 @@ func WithRateLimitAnnotations(rlDesc ...*v2.RateLimitDescription) annotations.An
 
 	return annos
 }
+
+// lastHostedEvent finds the latest already started scheduled event hosted by the user within the look-back window.
+// Canceled events are not activity, so only active ones count.
+func lastHostedEvent(ctx context.Context, client *calendly.Client, orgURI, userURI string, lookback time.Duration) (*time.Time, *v2.RateLimitDescription, error) {
+	now := time.Now()
+	filter := &calendly.EventFilterVars{
+		UserURI:      userURI,
+		Status:       calendly.ScheduledEventStatusActive,
+		MinStartTime: now.Add(-lookback),
+		MaxStartTime: now,
+		Sort:         "start_time:desc",
+	}
+
+	events, _, rl, err := client.ListScheduledEvents(ctx, orgURI, calendly.NewPaginationVars(1, ""), filter)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(events) == 0 {
+		return nil, rl, nil
+	}
+
+	start, err := time.Parse(time.RFC3339, events[0].StartTime)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse event start time: %w", err)
+	}
+
+	return &start, rl, nil
+}
@@ -0,0 +1,130 @@
+package connector
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/conductorone/baton-calendly/pkg/calendly"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	ent "github.com/conductorone/baton-sdk/pkg/types/entitlement"
+)
+
+// watermark is stored in the ETag of the organization resource between syncs. It holds the latest
+// membership update seen by the last full sync of the user role grants, the time of that sync and
+// a digest of the members who held the user role.
+type watermark struct {
+	UpdatedAt  time.Time `json:"updated_at"`
+	FullSyncAt time.Time `json:"full_sync_at"`
+	Members    string    `json:"members"`
+}
+
+// previousWatermark returns watermark of the last full sync when an incremental sync of the user role
+// grants is possible. Nil means all grants have to be emitted. That includes any change of the members
+// holding the user role, e.g. a role change or a removal, since reused grants would still hold the user role.
+func (c *Config) previousWatermark(resource *v2.Resource, memberships []calendly.OrgMembership) *watermark {
+	if c == nil || !c.IncrementalSync {
+		return nil
+	}
+
+	etag := &v2.ETag{}
+	annos := annotations.Annotations(resource.Annotations)
+	ok, err := annos.Pick(etag)
+	if err != nil || !ok || etag.EntitlementId != ent.NewEntitlementID(resource, OrgUserEntitlement) {
+		return nil
+	}
+
+	var w watermark
+	if err := json.Unmarshal([]byte(etag.Value), &w); err != nil {
+		return nil
+	}
+
+	if c.FullSyncInterval > 0 && time.Since(w.FullSyncAt) >= c.FullSyncInterval {
+		return nil
+	}
+
+	if w.Members != c.userMembersDigest(memberships) {
+		return nil
+	}
+
+	return &w
+}
+
+// watermarkAnnotations returns ETagMatch reusing user role grants of the previous sync when the sync is incremental,
+// or ETag with a new watermark when all grants are emitted.
+func (c *Config) watermarkAnnotations(resource *v2.Resource, prev *watermark, memberships []calendly.OrgMembership) (annotations.Annotations, error) {
+	if c == nil || !c.IncrementalSync {
+		return nil, nil
+	}
+
+	entitlementID := ent.NewEntitlementID(resource, OrgUserEntitlement)
+	annos := annotations.Annotations{}
+
+	if prev != nil {
+		annos.Update(&v2.ETagMatch{EntitlementId: entitlementID})
+		return annos, nil
+	}
+
+	w := watermark{
+		FullSyncAt: time.Now().UTC(),
+		Members:    c.userMembersDigest(memberships),
+	}
+	for _, m := range memberships {
+		if updated := membershipUpdatedAt(&m); updated.After(w.UpdatedAt) {
+			w.UpdatedAt = updated
+		}
+	}
+
+	value, err := json.Marshal(w)
+	if err != nil {
+		return nil, err
+	}
+
+	annos.Update(&v2.ETag{
+		Value:         string(value),
+		EntitlementId: entitlementID,
+	})
+
+	return annos, nil
+}
+
+// userMembersDigest returns digest of the users holding the user role among the synced memberships.
+func (c *Config) userMembersDigest(memberships []calendly.OrgMembership) string {
+	var users []string
+	for _, m := range memberships {
+		if m.Role == OrgUserEntitlement && m.User != nil && c.includeMembership(&m) {
+			users = append(users, m.User.ID)
+		}
+	}
+	slices.Sort(users)
+
+	sum := sha256.Sum256([]byte(strings.Join(users, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+// changedSince reports whether the membership or its user were created or updated after the watermark.
+func (w *watermark) changedSince(m *calendly.OrgMembership) bool {
+	return w == nil || !membershipUpdatedAt(m).Before(w.UpdatedAt)
+}
+
+// membershipUpdatedAt returns the latest creation or update time of the membership and its user.
+func membershipUpdatedAt(m *calendly.OrgMembership) time.Time {
+	var rv time.Time
+	timestamps := []string{m.CreatedAt, m.UpdatedAt}
+	if m.User != nil {
+		timestamps = append(timestamps, m.User.UpdatedAt)
+	}
+
+	for _, ts := range timestamps {
+		t, err := time.Parse(time.RFC3339, ts)
+		if err == nil && t.After(rv) {
+			rv = t
+		}
+	}
+
+	return rv
+}
@@ -0,0 +1,143 @@
+package connector
+
+import (
+	"testing"
+	"time"
+
+	"github.com/conductorone/baton-calendly/pkg/calendly"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+)
+
+func testMembership(userID, role, updatedAt string) calendly.OrgMembership {
+	return calendly.OrgMembership{
+		ID:        "https://api.calendly.com/organization_memberships/" + userID,
+		Role:      role,
+		CreatedAt: "2024-01-01T00:00:00Z",
+		UpdatedAt: updatedAt,
+		User: &calendly.User{
+			ID:        "https://api.calendly.com/users/" + userID,
+			Email:     userID + "@example.com",
+			UpdatedAt: "2024-01-01T00:00:00Z",
+		},
+	}
+}
+
+func TestChangedSince(t *testing.T) {
+	w := &watermark{UpdatedAt: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)}
+
+	userUpdated := testMembership("u", OrgUserEntitlement, "2024-01-01T00:00:00Z")
+	userUpdated.User.UpdatedAt = "2024-07-01T00:00:00Z"
+
+	tests := []struct {
+		name      string
+		watermark *watermark
+		m         calendly.OrgMembership
+		want      bool
+	}{
+		{name: "no watermark", watermark: nil, m: testMembership("u", OrgUserEntitlement, "2024-01-01T00:00:00Z"), want: true},
+		{name: "unchanged", watermark: w, m: testMembership("u", OrgUserEntitlement, "2024-05-31T23:59:59Z"), want: false},
+		{name: "updated at the watermark", watermark: w, m: testMembership("u", OrgUserEntitlement, "2024-06-01T00:00:00Z"), want: true},
+		{name: "membership updated", watermark: w, m: testMembership("u", OrgUserEntitlement, "2024-06-02T00:00:00Z"), want: true},
+		{name: "user updated", watermark: w, m: userUpdated, want: true},
+		{name: "invalid timestamp", watermark: w, m: testMembership("u", OrgUserEntitlement, "yesterday"), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.watermark.changedSince(&tt.m); got != tt.want {
+				t.Errorf("changedSince() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPreviousWatermark(t *testing.T) {
+	synced := []calendly.OrgMembership{
+		testMembership("a", OrgUserEntitlement, "2024-01-01T00:00:00Z"),
+		testMembership("b", OrgUserEntitlement, "2024-02-01T00:00:00Z"),
+		testMembership("c", OrgAdminEntitlement, "2024-03-01T00:00:00Z"),
+	}
+
+	tests := []struct {
+		name        string
+		config      *Config
+		memberships []calendly.OrgMembership
+		want        bool
+	}{
+		{
+			name:        "nothing changed",
+			config:      &Config{IncrementalSync: true},
+			memberships: synced,
+			want:        true,
+		},
+		{
+			name:   "user updated",
+			config: &Config{IncrementalSync: true},
+			memberships: []calendly.OrgMembership{
+				testMembership("a", OrgUserEntitlement, "2024-08-01T00:00:00Z"),
+				synced[1],
+				synced[2],
+			},
+			want: true,
+		},
+		{
+			name:   "user became admin",
+			config: &Config{IncrementalSync: true},
+			memberships: []calendly.OrgMembership{
+				testMembership("a", OrgAdminEntitlement, "2024-08-01T00:00:00Z"),
+				synced[1],
+				synced[2],
+			},
+			want: false,
+		},
+		{
+			name:   "admin became user",
+			config: &Config{IncrementalSync: true},
+			memberships: []calendly.OrgMembership{
+				synced[0],
+				synced[1],
+				testMembership("c", OrgUserEntitlement, "2024-08-01T00:00:00Z"),
+			},
+			want: false,
+		},
+		{
+			name:        "user removed",
+			config:      &Config{IncrementalSync: true},
+			memberships: synced[1:],
+			want:        false,
+		},
+		{
+			name:        "incremental sync disabled",
+			config:      &Config{},
+			memberships: synced,
+			want:        false,
+		},
+		{
+			name:        "full sync due",
+			config:      &Config{IncrementalSync: true, FullSyncInterval: time.Nanosecond},
+			memberships: synced,
+			want:        false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resource := &v2.Resource{Id: &v2.ResourceId{ResourceType: orgResourceType.Id, Resource: "https://api.calendly.com/organizations/o"}}
+
+			annos, err := (&Config{IncrementalSync: true}).watermarkAnnotations(resource, nil, synced)
+			if err != nil {
+				t.Fatalf("watermarkAnnotations: %v", err)
+			}
+			resource.Annotations = annos
+
+			got := tt.config.previousWatermark(resource, tt.memberships)
+			if (got != nil) != tt.want {
+				t.Fatalf("previousWatermark() = %+v, want incremental %v", got, tt.want)
+			}
+
+			if got != nil && !got.UpdatedAt.Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)) {
+				t.Errorf("UpdatedAt = %s, want the latest synced update", got.UpdatedAt)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/conductorone/baton-calendly/pkg/calendly"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+)
+
+const (
+	// StaleInvitationReport only reports the stale invitations.
+	StaleInvitationReport = "report"
+	// StaleInvitationResend cancels the stale invitation and invites the email again.
+	StaleInvitationResend = "resend"
+	// StaleInvitationExpire cancels the stale invitation.
+	StaleInvitationExpire = "expire"
+)
+
+// StaleInvitationActions lists the supported stale invitation actions.
+var StaleInvitationActions = []string{
+	StaleInvitationReport,
+	StaleInvitationResend,
+	StaleInvitationExpire,
+}
+
+// StaleInvitationResult describes a single pending invitation older than the threshold and the outcome of the action.
+type StaleInvitationResult struct {
+	Email         string `json:"email"`
+	InvitationURI string `json:"invitation_uri"`
+	CreatedAt     string `json:"created_at"`
+	AgeDays       int    `json:"age_days"`
+	Action        string `json:"action"`
+	// NewerInvitation is set when the email has a pending invitation within the threshold as well,
+	// the stale one is then canceled but the email isn't invited again.
+	NewerInvitation bool   `json:"newer_invitation,omitempty"`
+	Done            bool   `json:"done"`
+	Error           string `json:"error,omitempty"`
+}
+
+// StaleInvitationOptions configures the stale invitation policy.
+type StaleInvitationOptions struct {
+	// OlderThan is the age after which a pending invitation is stale.
+	OlderThan time.Duration
+	// Action is one of StaleInvitationActions.
+	Action string
+	// Confirm performs the action. Without it only the report is produced.
+	Confirm bool
+}
+
+// ProcessStaleInvitations finds pending invitations older than the threshold and resends or expires them when confirmed.
+// Duplicate stale invitations of an email are all canceled but the email is invited again only once,
+// and not at all when it has a newer pending invitation.
+func (c *Calendly) ProcessStaleInvitations(ctx context.Context, opts *StaleInvitationOptions) ([]*StaleInvitationResult, error) {
+	l := ctxzap.Extract(ctx)
+
+	if opts.OlderThan <= 0 {
+		return nil, fmt.Errorf("calendly-connector: invitation age threshold must be positive")
+	}
+
+	if opts.Action != StaleInvitationReport && opts.Action != StaleInvitationResend && opts.Action != StaleInvitationExpire {
+		return nil, fmt.Errorf("calendly-connector: unknown stale invitation action %s", opts.Action)
+	}
+
+	u, _, err := c.client.GetCurrentUser(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("calendly-connector: failed to get current user details: %w", err)
+	}
+
+	// collect all pages first, canceled invitations would shift the following pages
+	now := time.Now()
+	var rv []*StaleInvitationResult
+	newer := make(map[string]bool)
+	page := ""
+	for {
+		invitations, nextPage, _, err := c.client.ListUserInvitations(ctx, u.OrgURI, calendly.InvitationStatusPending, calendly.NewPaginationVars(c.config.pageSize(c.client), page), nil)
+		if err != nil {
+			return nil, fmt.Errorf("calendly-connector: failed to list org invitations: %w", err)
+		}
+
+		for _, i := range invitations {
+			if !c.config.includeEmail(i.Email) {
+				continue
+			}
+
+			created, err := time.Parse(time.RFC3339, i.CreatedAt)
+			if err != nil {
+				return nil, fmt.Errorf("calendly-connector: failed to parse creation time of invitation %s: %w", i.ID, err)
+			}
+
+			age := now.Sub(created)
+			if age < opts.OlderThan {
+				newer[strings.ToLower(i.Email)] = true
+				continue
+			}
+
+			rv = append(rv, &StaleInvitationResult{
+				Email:         i.Email,
+				InvitationURI: i.ID,
+				CreatedAt:     i.CreatedAt,
+				AgeDays:       int(age / (24 * time.Hour)),
+				Action:        opts.Action,
+			})
+		}
+
+		if nextPage == "" {
+			break
+		}
+		page = nextPage
+	}
+
+	for _, r := range rv {
+		r.NewerInvitation = newer[strings.ToLower(r.Email)]
+	}
+
+	if !opts.Confirm || opts.Action == StaleInvitationReport {
+		l.Info("calendly-connector: dry run, no invitations changed", zap.String("action", opts.Action), zap.Int("stale_invitations", len(rv)))
+		return rv, nil
+	}
+
+	reinvited := make(map[string]bool)
+	for _, r := range rv {
+		err := c.applyStaleInvitationAction(ctx, u.OrgURI, r, reinvited)
+		if err != nil {
+			r.Error = err.Error()
+			l.Error(
+				"calendly-connector: failed to process stale invitation",
+				zap.String("action", r.Action),
+				zap.String("email", r.Email),
+				zap.String("invitation_uri", r.InvitationURI),
+				zap.Error(err),
+			)
+
+			continue
+		}
+
+		r.Done = true
+		l.Info(
+			"calendly-connector: processed stale invitation",
+			zap.String("action", r.Action),
+			zap.String("email", r.Email),
+			zap.String("invitation_uri", r.InvitationURI),
+			zap.Int("age_days", r.AgeDays),
+		)
+	}
+
+	return rv, nil
+}
+
+func (c *Calendly) applyStaleInvitationAction(ctx context.Context, orgURI string, r *StaleInvitationResult, reinvited map[string]bool) error {
+	_, err := c.client.RemoveUserInvitation(ctx, orgURI, parseResourceID(r.InvitationURI))
+	if err != nil {
+		return fmt.Errorf("failed to cancel invitation: %w", err)
+	}
+
+	email := strings.ToLower(r.Email)
+	if r.Action != StaleInvitationResend || r.NewerInvitation || reinvited[email] {
+		return nil
+	}
+
+	_, err = c.client.InviteOrgMember(ctx, orgURI, r.Email)
+	if err != nil {
+		return fmt.Errorf("invitation canceled but failed to invite again: %w", err)
+	}
+
+	reinvited[email] = true
+
+	return nil
+}
@@ -0,0 +1,167 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/conductorone/baton-calendly/pkg/calendly"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// offboardingImpact describes what a member leaves behind when removed from the organization.
+type offboardingImpact struct {
+	UpcomingEvents  int
+	OwnedEventTypes int
+	CanceledEvents  int
+}
+
+func (i *offboardingImpact) empty() bool {
+	return i.UpcomingEvents == 0 && i.OwnedEventTypes == 0
+}
+
+func (i *offboardingImpact) annotations() (annotations.Annotations, error) {
+	impact, err := structpb.NewStruct(map[string]interface{}{
+		"upcoming_events":   i.UpcomingEvents,
+		"owned_event_types": i.OwnedEventTypes,
+		"canceled_events":   i.CanceledEvents,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("calendly-connector: failed to create offboarding annotation: %w", err)
+	}
+
+	annos := annotations.Annotations{}
+	annos.Append(impact)
+
+	return annos, nil
+}
+
+// forceOffboarding reports whether members are removed even when they have upcoming meetings or own event types.
+func (c *Config) forceOffboarding() bool {
+	return c != nil && c.ForceOffboarding
+}
+
+// checkOffboarding counts upcoming meetings hosted by the member and event types the member owns.
+// The counts are returned as annotations. Unless forced, a member leaving any of them behind
+// is refused with FailedPrecondition, since removal would orphan the booked meetings.
+// Annotations don't travel with errors, so a refusal carries the counts in its message and log.
+// With meeting cancellation enabled, upcoming meetings don't block the removal and are canceled
+// once the removal is allowed to go ahead.
+func checkOffboarding(ctx context.Context, client *calendly.Client, config *Config, orgURI string, user *calendly.User) (annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	impact, err := countOffboardingImpact(ctx, client, config, orgURI, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	cancelMeetings := config.cancelMeetingsOnOffboarding() && impact.UpcomingEvents > 0
+
+	blocking := *impact
+	if cancelMeetings {
+		blocking.UpcomingEvents = 0
+	}
+
+	if !blocking.empty() {
+		if !config.forceOffboarding() {
+			l.Info(
+				"calendly-connector: refused to remove member with upcoming meetings or owned event types",
+				zap.String("email", user.Email),
+				zap.Int("upcoming_events", blocking.UpcomingEvents),
+				zap.Int("owned_event_types", blocking.OwnedEventTypes),
+			)
+
+			return nil, status.Errorf(
+				codes.FailedPrecondition,
+				"calendly-connector: %s hosts %d upcoming meetings and owns %d event types, reassign them or enable forced offboarding",
+				user.Email,
+				blocking.UpcomingEvents,
+				blocking.OwnedEventTypes,
+			)
+		}
+
+		l.Warn(
+			"calendly-connector: removing member with upcoming meetings or owned event types",
+			zap.String("email", user.Email),
+			zap.Int("upcoming_events", blocking.UpcomingEvents),
+			zap.Int("owned_event_types", blocking.OwnedEventTypes),
+		)
+	}
+
+	if cancelMeetings {
+		results, err := cancelUpcomingMeetings(ctx, client, config, orgURI, user, true)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, r := range results {
+			if !r.Canceled {
+				return nil, fmt.Errorf("calendly-connector: failed to cancel meeting %s of %s: %s", r.EventURI, user.Email, r.Error)
+			}
+		}
+
+		impact.CanceledEvents = len(results)
+	}
+
+	return impact.annotations()
+}
+
+func countOffboardingImpact(ctx context.Context, client *calendly.Client, config *Config, orgURI, userURI string) (*offboardingImpact, error) {
+	var rv offboardingImpact
+
+	filter := &calendly.EventFilterVars{
+		UserURI:      userURI,
+		Status:       calendly.ScheduledEventStatusActive,
+		MinStartTime: time.Now(),
+	}
+
+	page := ""
+	for {
+		events, nextPage, _, err := client.ListScheduledEvents(ctx, orgURI, calendly.NewPaginationVars(config.pageSize(client), page), filter)
+		if err != nil {
+			return nil, fmt.Errorf("calendly-connector: failed to list upcoming scheduled events: %w", err)
+		}
+
+		rv.UpcomingEvents += len(events)
+		if nextPage == "" {
+			break
+		}
+		page = nextPage
+	}
+
+	page = ""
+	for {
+		eventTypes, nextPage, _, err := client.ListUserEventTypes(ctx, userURI, calendly.NewPaginationVars(config.pageSize(client), page))
+		if err != nil {
+			return nil, fmt.Errorf("calendly-connector: failed to list user event types: %w", err)
+		}
+
+		for _, et := range eventTypes {
+			if et.Profile != nil && et.Profile.Owner == userURI {
+				rv.OwnedEventTypes++
+			}
+		}
+
+		if nextPage == "" {
+			break
+		}
+		page = nextPage
+	}
+
+	return &rv, nil
+}
+
+// mergeAnnotations returns the annotations of all the sets in one.
+func mergeAnnotations(sets ...annotations.Annotations) annotations.Annotations {
+	var rv annotations.Annotations
+	for _, s := range sets {
+		rv = append(rv, s...)
+	}
+
+	return rv
+}
@@ -6,6 +6,7 @@ import (
 	"slices"
 
 	"github.com/conductorone/baton-calendly/pkg/calendly"
+	"github.com/conductorone/baton-calendly/pkg/scim"
 	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
 	"github.com/conductorone/baton-sdk/pkg/annotations"
 	"github.com/conductorone/baton-sdk/pkg/pagination"
@@ -24,10 +25,13 @@ const (
 	OrgOwnerEntitlement       = "owner"
 	OrgPendingUserEntitlement = "pending_user"
 
-	InvitationsType = "invitations"
+	InvitationsType      = "invitations"
+	DeactivatedUsersType = "deactivated_users"
 )
 
-// TODO: add support for more roles, more information about all roles:
+// OrgRoles are the organization roles always exposed as entitlements. Any other role returned by the API,
+// e.g. team manager on Teams and Enterprise plans, is discovered from memberships during sync.
+// More information about all roles:
 // https://help.calendly.com/hc/en-us/articles/4410722852759-User-roles-and-permissions
 var OrgRoles = []string{
 	OrgUserEntitlement,
@@ -35,9 +39,15 @@ var OrgRoles = []string{
 	OrgOwnerEntitlement,
 }
 
+// errOwnerImmutable is returned for any attempt to remove the organization owner.
+var errOwnerImmutable = status.Error(codes.FailedPrecondition, "calendly-connector: organization owner can't be removed, transfer the ownership in Calendly first")
+
 type orgBuilder struct {
 	client       *calendly.Client
+	scim         *scim.Client
 	resourceType *v2.ResourceType
+	config       *Config
+	snapshots    *snapshotStore
 }
 
 func (o *orgBuilder) ResourceType(ctx context.Context) *v2.ResourceType {
@@ -53,6 +63,10 @@ func orgResource(org *calendly.Organization) (*v2.Resource, error) {
 		org.ID,
 		rs.WithAnnotation(
 			&v2.ChildResourceType{ResourceTypeId: userResourceType.Id},
+			&v2.ChildResourceType{ResourceTypeId: groupResourceType.Id},
+			&v2.ChildResourceType{ResourceTypeId: eventTypeResourceType.Id},
+			&v2.ChildResourceType{ResourceTypeId: routingFormResourceType.Id},
+			&v2.ChildResourceType{ResourceTypeId: webhookSubscriptionResourceType.Id},
 		),
 	)
 	if err != nil {
@@ -67,6 +81,9 @@ func orgResource(org *calendly.Organization) (*v2.Resource, error) {
 func (o *orgBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId, pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
 	var rv []*v2.Resource
 
+	// organizations are listed first in every sync, so drop memberships and invitations fetched by a previous one
+	o.snapshots.reset()
+
 	u, rlu, err := o.client.GetCurrentUser(ctx)
 	if err != nil {
 		return nil, "", nil, fmt.Errorf("calendly-connector: failed to get current user details: %w", err)
@@ -99,13 +116,22 @@ func (o *orgBuilder) Entitlements(ctx context.Context, resource *v2.Resource, _
 	}
 	rv = append(rv, ent.NewAssignmentEntitlement(resource, OrgPendingUserEntitlement, iaOptions...))
 
+	snapshot, err := o.snapshots.get(ctx, resource.Id.Resource)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
 	// entitlements representing roles in the organization
-	for _, role := range OrgRoles {
+	for _, role := range orgRoles(snapshot.memberships) {
 		permissionOptions := []ent.EntitlementOption{
 			ent.WithGrantableTo(userResourceType),
 			ent.WithDisplayName(fmt.Sprintf("%s role", role)),
 			ent.WithDescription(fmt.Sprintf("%s role in the organization", role)),
 		}
+		// the owner can't be removed or changed through the API, only transferred in Calendly
+		if role == OrgOwnerEntitlement {
+			permissionOptions = append(permissionOptions, ent.WithAnnotation(&v2.EntitlementImmutable{}))
+		}
 		rv = append(rv, ent.NewPermissionEntitlement(resource, role, permissionOptions...))
 	}
 
@@ -115,38 +141,46 @@ func (o *orgBuilder) Entitlements(ctx context.Context, resource *v2.Resource, _
 // Grants returns slice of membership and permission grants for the org.
 func (o *orgBuilder) Grants(ctx context.Context, resource *v2.Resource, pToken *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
 	var rv []*v2.Grant
+	var annos annotations.Annotations
 
 	bag, page, err := parsePageToken(pToken.Token, resource.Id)
 	if err != nil {
 		return nil, "", nil, fmt.Errorf("calendly-connector: failed to parse page token: %w", err)
 	}
 
-	var rldata []*v2.RateLimitDescription
-
 	switch bag.ResourceTypeID() {
 	case resource.Id.ResourceType:
 		bag.Pop()
 		bag.Push(pagination.PageState{
 			ResourceTypeID: userResourceType.Id,
 		})
-		bag.Push(pagination.PageState{
-			ResourceTypeID: InvitationsType,
-		})
+		if o.config.includeInvitations() {
+			bag.Push(pagination.PageState{
+				ResourceTypeID: InvitationsType,
+			})
+		}
 
 	case InvitationsType:
-		pgVars := calendly.NewPaginationVars(ResourcesPageSize, page)
-		invitations, nextPage, rli, err := o.client.ListUserInvitations(ctx, resource.Id.Resource, pgVars, nil)
+		snapshot, err := o.snapshots.get(ctx, resource.Id.Resource)
 		if err != nil {
-			return nil, "", nil, fmt.Errorf("calendly-connector: failed to list org invitations: %w", err)
+			return nil, "", nil, err
+		}
+
+		invitations, nextPage, err := pageOf(snapshot.invitations, page, o.config.pageSize(nil))
+		if err != nil {
+			return nil, "", nil, err
 		}
 
-		rldata = append(rldata, rli)
 		err = bag.Next(nextPage)
 		if err != nil {
 			return nil, "", nil, err
 		}
 
 		for _, i := range invitations {
+			if !o.config.includeInvitation(&i) {
+				continue
+			}
+
 			userId, err := rs.NewResourceID(userResourceType, i.Email)
 			if err != nil {
 				return nil, "", nil, fmt.Errorf("calendly-connector: failed to create user resource id: %w", err)
@@ -156,10 +190,14 @@ func (o *orgBuilder) Grants(ctx context.Context, resource *v2.Resource, pToken *
 		}
 
 	case userResourceType.Id:
-		pgVars := calendly.NewPaginationVars(ResourcesPageSize, page)
-		memberships, nextPage, err := o.client.ListUsersUnderOrg(ctx, resource.Id.Resource, pgVars, nil)
+		snapshot, err := o.snapshots.get(ctx, resource.Id.Resource)
 		if err != nil {
-			return nil, "", nil, fmt.Errorf("calendly-connector: failed to list users in org: %w", err)
+			return nil, "", nil, err
+		}
+
+		memberships, nextPage, err := pageOf(snapshot.memberships, page, o.config.pageSize(nil))
+		if err != nil {
+			return nil, "", nil, err
 		}
 
 		err = bag.Next(nextPage)
@@ -167,10 +205,27 @@ func (o *orgBuilder) Grants(ctx context.Context, resource *v2.Resource, pToken *
 			return nil, "", nil, err
 		}
 
+		// in incremental sync unchanged user role grants are reused from the previous sync
+		prev := o.config.previousWatermark(resource, snapshot.memberships)
+		if page == "" {
+			annos, err = o.config.watermarkAnnotations(resource, prev, snapshot.memberships)
+			if err != nil {
+				return nil, "", nil, fmt.Errorf("calendly-connector: failed to create sync watermark: %w", err)
+			}
+		}
+
 		for _, m := range memberships {
+			if !o.config.includeMembership(&m) {
+				continue
+			}
+
+			if m.Role == OrgUserEntitlement && !prev.changedSince(&m) {
+				continue
+			}
+
 			// check for valid role
-			if !slices.Contains(OrgRoles, m.Role) {
-				return nil, "", nil, fmt.Errorf("calendly-connector: role %s not found", m.Role)
+			if m.Role == "" {
+				return nil, "", nil, fmt.Errorf("calendly-connector: missing role of membership %s", m.ID)
 			}
 
 			userId, err := rs.NewResourceID(userResourceType, m.User.ID)
@@ -178,7 +233,15 @@ func (o *orgBuilder) Grants(ctx context.Context, resource *v2.Resource, pToken *
 				return nil, "", nil, fmt.Errorf("calendly-connector: failed to create user resource id: %w", err)
 			}
 
-			rv = append(rv, grant.NewGrant(resource, m.Role, userId))
+			var grantOptions []grant.GrantOption
+			if metadata := snapshot.invitationMetadata(m.User); metadata != nil {
+				grantOptions = append(grantOptions, grant.WithGrantMetadata(metadata))
+			}
+			if m.Role == OrgOwnerEntitlement {
+				grantOptions = append(grantOptions, grant.WithAnnotation(&v2.GrantImmutable{}))
+			}
+
+			rv = append(rv, grant.NewGrant(resource, m.Role, userId, grantOptions...))
 		}
 
 	default:
@@ -190,10 +253,11 @@ func (o *orgBuilder) Grants(ctx context.Context, resource *v2.Resource, pToken *
 		return nil, "", nil, err
 	}
 
-	return rv, next, WithRateLimitAnnotations(rldata...), nil
+	return rv, next, annos, nil
 }
 
-// Grant method is only used for user invitations to the organization.
+// Grant method is used for user invitations to the organization. With SCIM enabled,
+// the user role can be granted as well, which creates or reactivates the SCIM user.
 func (o *orgBuilder) Grant(ctx context.Context, principal *v2.Resource, entitlement *v2.Entitlement) (annotations.Annotations, error) {
 	l := ctxzap.Extract(ctx)
 
@@ -208,6 +272,10 @@ func (o *orgBuilder) Grant(ctx context.Context, principal *v2.Resource, entitlem
 		return nil, status.Error(codes.InvalidArgument, "calendly-connector: only users can be granted organization membership")
 	}
 
+	if entitlement.Slug == OrgUserEntitlement && o.scim != nil {
+		return o.provisionSCIMUser(ctx, principal)
+	}
+
 	// check for valid role - we can't grant roles - only invitations are allowed
 	if entitlement.Slug != OrgPendingUserEntitlement {
 		l.Warn(
@@ -218,7 +286,7 @@ func (o *orgBuilder) Grant(ctx context.Context, principal *v2.Resource, entitlem
 		return nil, status.Error(codes.InvalidArgument, "calendly-connector: only user role can be granted in organization")
 	}
 
-	rli, err := o.client.InviteOrgMember(ctx, principal.ParentResourceId.Resource, principal.Id.Resource)
+	rli, err := o.client.InviteOrgMember(ctx, entitlement.Resource.Id.Resource, principal.Id.Resource)
 	if err != nil {
 		return nil, fmt.Errorf("calendly-connector: failed to invite user to org: %w", err)
 	}
@@ -227,7 +295,7 @@ func (o *orgBuilder) Grant(ctx context.Context, principal *v2.Resource, entitlem
 }
 
 // Revoke method is only used for canceling invitations and removing users from the organization.
-// Note that we can't remove owner from the organization.
+// The owner can't be removed, which is refused before calling the API.
 func (o *orgBuilder) Revoke(ctx context.Context, grant *v2.Grant) (annotations.Annotations, error) {
 	l := ctxzap.Extract(ctx)
 
@@ -245,6 +313,10 @@ func (o *orgBuilder) Revoke(ctx context.Context, grant *v2.Grant) (annotations.A
 		return nil, status.Error(codes.InvalidArgument, "calendly-connector: only users can be revoked from organization")
 	}
 
+	if entitlement.Slug == OrgOwnerEntitlement {
+		return nil, errOwnerImmutable
+	}
+
 	// check for valid role - we can't revoke roles - only invitations are allowed
 	if entitlement.Slug != OrgUserEntitlement && entitlement.Slug != OrgPendingUserEntitlement {
 		l.Warn(
@@ -256,57 +328,150 @@ func (o *orgBuilder) Revoke(ctx context.Context, grant *v2.Grant) (annotations.A
 		return nil, status.Error(codes.InvalidArgument, "calendly-connector: only user memberships and invitations can be revoked in organization")
 	}
 
+	orgURI := entitlement.Resource.Id.Resource
+
 	if entitlement.Slug == OrgUserEntitlement {
-		memberships, _, err := o.client.ListUsersUnderOrg(ctx, principal.ParentResourceId.Resource, nil, calendly.NewFilterVars(principal.DisplayName))
+		membership, err := o.findMembership(ctx, orgURI, principal)
 		if err != nil {
-			return nil, fmt.Errorf("calendly-connector: failed to list users in org: %w", err)
+			return nil, err
+		}
+
+		if membership.User == nil {
+			return nil, status.Error(codes.Internal, "calendly-connector: missing user of the membership")
+		}
+
+		if membership.Role == OrgOwnerEntitlement {
+			return nil, errOwnerImmutable
 		}
 
-		if len(memberships) == 0 {
-			return nil, status.Error(codes.NotFound, "calendly-connector: user not found in org")
+		impact, err := checkOffboarding(ctx, o.client, o.config, orgURI, membership.User)
+		if err != nil {
+			return nil, err
 		}
 
-		if len(memberships) > 1 {
-			return nil, status.Error(codes.Internal, "calendly-connector: multiple users found in org")
+		// SCIM provisioned users are deactivated, so they can be reactivated later
+		if o.scim != nil {
+			deactivated, err := o.deactivateSCIMUser(ctx, membership.User.Email)
+			if err != nil {
+				return nil, err
+			}
+
+			if deactivated {
+				return impact, nil
+			}
 		}
 
-		membershipURI := memberships[0].ID
-		membershipID := parseResourceID(membershipURI)
+		membershipID := parseResourceID(membership.ID)
 		rlo, err := o.client.RemoveOrgMember(ctx, membershipID)
 		if err != nil {
 			return nil, fmt.Errorf("calendly-connector: failed to remove user from org: %w", err)
 		}
 
-		return WithRateLimitAnnotations(rlo), nil
+		return mergeAnnotations(impact, WithRateLimitAnnotations(rlo)), nil
 	}
 
 	if entitlement.Slug == OrgPendingUserEntitlement {
-		invitations, _, rli, err := o.client.ListUserInvitations(ctx, principal.ParentResourceId.Resource, nil, calendly.NewFilterVars(principal.DisplayName))
-		if err != nil {
-			return nil, fmt.Errorf("calendly-connector: failed to list org invitations: %w", err)
+		email := principalEmail(principal)
+		if email == "" {
+			return nil, status.Error(codes.InvalidArgument, "calendly-connector: missing email of the invited user")
+		}
+
+		// collect all pages first, removed invitations would shift the following pages
+		var invitations []calendly.Invitation
+		page := ""
+		for {
+			i, nextPage, _, err := o.client.ListUserInvitations(ctx, orgURI, calendly.InvitationStatusPending, calendly.NewPaginationVars(o.config.pageSize(o.client), page), calendly.NewFilterVars(email))
+			if err != nil {
+				return nil, fmt.Errorf("calendly-connector: failed to list org invitations: %w", err)
+			}
+
+			invitations = append(invitations, i...)
+			if nextPage == "" {
+				break
+			}
+			page = nextPage
 		}
 
 		if len(invitations) == 0 {
 			return nil, status.Error(codes.NotFound, "calendly-connector: user invitation not found in org")
 		}
 
-		// do not throw error in case of duplicates, just take the first one
-		invitationURI := invitations[0].ID
-		invitationID := parseResourceID(invitationURI)
-		rlri, err := o.client.RemoveUserInvitation(ctx, principal.ParentResourceId.Resource, invitationID)
-		if err != nil {
-			return nil, fmt.Errorf("calendly-connector: failed to remove user invitation: %w", err)
+		// duplicates are removed as well, so the person can't accept an older invitation
+		var rldata []*v2.RateLimitDescription
+		for _, i := range invitations {
+			rlri, err := o.client.RemoveUserInvitation(ctx, orgURI, parseResourceID(i.ID))
+			if err != nil {
+				return nil, fmt.Errorf("calendly-connector: failed to remove user invitation %s: %w", i.ID, err)
+			}
+
+			rldata = append(rldata, rlri)
+		}
+
+		if len(invitations) > 1 {
+			l.Info(
+				"calendly-connector: removed duplicate pending invitations",
+				zap.String("email", email),
+				zap.Int("invitations", len(invitations)),
+			)
 		}
 
-		return WithRateLimitAnnotations(rli, rlri), nil
+		return WithRateLimitAnnotations(rldata...), nil
 	}
 
 	return nil, nil
 }
 
-func newOrgBuilder(client *calendly.Client) *orgBuilder {
+// orgRoles returns the known organization roles followed by any other roles discovered in the memberships.
+func orgRoles(memberships []calendly.OrgMembership) []string {
+	rv := slices.Clone(OrgRoles)
+	for _, m := range memberships {
+		if m.Role != "" && !slices.Contains(rv, m.Role) {
+			rv = append(rv, m.Role)
+		}
+	}
+
+	return rv
+}
+
+// findMembership resolves the organization membership of the principal by its user URI,
+// falling back to the email from the user trait.
+func (o *orgBuilder) findMembership(ctx context.Context, orgURI string, principal *v2.Resource) (*calendly.OrgMembership, error) {
+	var memberships []calendly.OrgMembership
+	if isURI(principal.Id.Resource) {
+		m, _, err := o.client.ListUsersUnderOrg(ctx, orgURI, nil, calendly.NewUserFilterVars(principal.Id.Resource))
+		if err != nil {
+			return nil, fmt.Errorf("calendly-connector: failed to list users in org: %w", err)
+		}
+
+		memberships = m
+	}
+
+	if email := principalEmail(principal); len(memberships) == 0 && email != "" {
+		m, _, err := o.client.ListUsersUnderOrg(ctx, orgURI, nil, calendly.NewFilterVars(email))
+		if err != nil {
+			return nil, fmt.Errorf("calendly-connector: failed to list users in org: %w", err)
+		}
+
+		memberships = m
+	}
+
+	if len(memberships) == 0 {
+		return nil, status.Error(codes.NotFound, "calendly-connector: user not found in org")
+	}
+
+	if len(memberships) > 1 {
+		return nil, status.Error(codes.Internal, "calendly-connector: multiple users found in org")
+	}
+
+	return &memberships[0], nil
+}
+
+func newOrgBuilder(client *calendly.Client, scimClient *scim.Client, config *Config, snapshots *snapshotStore) *orgBuilder {
 	return &orgBuilder{
 		client:       client,
+		scim:         scimClient,
 		resourceType: orgResourceType,
+		config:       config,
+		snapshots:    snapshots,
 	}
 }
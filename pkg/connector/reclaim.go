@@ -0,0 +1,146 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/conductorone/baton-calendly/pkg/calendly"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+)
+
+// ReclaimResult describes a single inactive member found during seat reclamation and the outcome of its removal.
+type ReclaimResult struct {
+	Email         string `json:"email"`
+	Name          string `json:"name"`
+	Role          string `json:"role"`
+	UserURI       string `json:"user_uri"`
+	MembershipURI string `json:"membership_uri"`
+	Removed       bool   `json:"removed"`
+	Error         string `json:"error,omitempty"`
+
+	// user is the membership user, offboarding checks need the full user
+	user *calendly.User
+}
+
+// ReclaimOptions configures seat reclamation.
+type ReclaimOptions struct {
+	// InactiveFor is the period without any hosted scheduled event after which a member is considered inactive.
+	InactiveFor time.Duration
+	// AllowList contains emails of members that are never reclaimed.
+	AllowList []string
+	// Confirm removes the inactive members. Without it only the report is produced.
+	Confirm bool
+}
+
+// ReclaimInactiveSeats finds organization members, other than owners, the token owner and allow-listed ones,
+// who haven't hosted any scheduled event in the inactivity period and removes them when confirmed.
+func (c *Calendly) ReclaimInactiveSeats(ctx context.Context, opts *ReclaimOptions) ([]*ReclaimResult, error) {
+	l := ctxzap.Extract(ctx)
+
+	if opts.InactiveFor <= 0 {
+		return nil, fmt.Errorf("calendly-connector: inactivity period must be positive")
+	}
+
+	allowList := make([]string, 0, len(opts.AllowList))
+	for _, email := range opts.AllowList {
+		allowList = append(allowList, strings.ToLower(strings.TrimSpace(email)))
+	}
+
+	u, _, err := c.client.GetCurrentUser(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("calendly-connector: failed to get current user details: %w", err)
+	}
+
+	var rv []*ReclaimResult
+	page := ""
+	for {
+		memberships, nextPage, err := c.client.ListUsersUnderOrg(ctx, u.OrgURI, calendly.NewPaginationVars(c.config.pageSize(c.client), page), nil)
+		if err != nil {
+			return nil, fmt.Errorf("calendly-connector: failed to list users in org: %w", err)
+		}
+
+		for _, m := range memberships {
+			if m.Role == OrgOwnerEntitlement || slices.Contains(allowList, strings.ToLower(m.User.Email)) {
+				continue
+			}
+
+			// removing the token owner would revoke the token the connector runs with
+			if m.User.ID == u.ID {
+				continue
+			}
+
+			if err := c.config.waitForRateLimit(ctx, c.client); err != nil {
+				return nil, err
+			}
+
+			lastActivity, _, err := lastHostedEvent(ctx, c.client, u.OrgURI, m.User.ID, opts.InactiveFor)
+			if err != nil {
+				return nil, fmt.Errorf("calendly-connector: failed to get user last activity: %w", err)
+			}
+
+			if lastActivity != nil {
+				continue
+			}
+
+			rv = append(rv, &ReclaimResult{
+				Email:         m.User.Email,
+				Name:          m.User.FullName,
+				Role:          m.Role,
+				UserURI:       m.User.ID,
+				MembershipURI: m.ID,
+				user:          m.User,
+			})
+		}
+
+		if nextPage == "" {
+			break
+		}
+		page = nextPage
+	}
+
+	if !opts.Confirm {
+		l.Info("calendly-connector: dry run, no members removed", zap.Int("inactive_members", len(rv)))
+		return rv, nil
+	}
+
+	for _, r := range rv {
+		_, err := checkOffboarding(ctx, c.client, c.config, u.OrgURI, r.user)
+		if err != nil {
+			r.Error = err.Error()
+			l.Warn(
+				"calendly-connector: skipped removal of inactive member",
+				zap.String("email", r.Email),
+				zap.String("membership_uri", r.MembershipURI),
+				zap.Error(err),
+			)
+
+			continue
+		}
+
+		_, err = c.client.RemoveOrgMember(ctx, parseResourceID(r.MembershipURI))
+		if err != nil {
+			r.Error = err.Error()
+			l.Error(
+				"calendly-connector: failed to remove inactive member",
+				zap.String("email", r.Email),
+				zap.String("membership_uri", r.MembershipURI),
+				zap.Error(err),
+			)
+
+			continue
+		}
+
+		r.Removed = true
+		l.Info(
+			"calendly-connector: removed inactive member",
+			zap.String("email", r.Email),
+			zap.String("membership_uri", r.MembershipURI),
+		)
+	}
+
+	return rv, nil
+}
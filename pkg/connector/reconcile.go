@@ -0,0 +1,340 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/conductorone/baton-calendly/pkg/calendly"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+)
+
+const (
+	DesiredRoleMember  = "member"
+	DesiredRoleAdmin   = "admin"
+	DesiredRolePending = "pending"
+)
+
+// DesiredRoles lists the roles accepted in the desired state file.
+var DesiredRoles = []string{
+	DesiredRoleMember,
+	DesiredRoleAdmin,
+	DesiredRolePending,
+}
+
+const (
+	// ReconcileInvite invites the email to the organization.
+	ReconcileInvite = "invite"
+	// ReconcileRemove removes the member from the organization.
+	ReconcileRemove = "remove"
+	// ReconcileCancelInvitation cancels pending invitations of the email.
+	ReconcileCancelInvitation = "cancel_invitation"
+	// ReconcileUnsupported is a difference the connector can't change, e.g. a role change. It is only reported.
+	ReconcileUnsupported = "unsupported"
+)
+
+// DesiredState is the intended membership of organizations, usually kept in a YAML or JSON file.
+type DesiredState struct {
+	Organizations []DesiredOrganization `yaml:"organizations" json:"organizations"`
+}
+
+// DesiredOrganization lists intended members of the organization. Empty organization URI means
+// the organization of the token owner.
+type DesiredOrganization struct {
+	Organization string          `yaml:"organization" json:"organization"`
+	Members      []DesiredMember `yaml:"members" json:"members"`
+}
+
+type DesiredMember struct {
+	Email string `yaml:"email" json:"email"`
+	Role  string `yaml:"role" json:"role"`
+}
+
+// ReconcileAction is a single difference between the desired and the live state and the outcome of its change.
+type ReconcileAction struct {
+	Organization string `json:"organization"`
+	Email        string `json:"email"`
+	Action       string `json:"action"`
+	Current      string `json:"current"`
+	Desired      string `json:"desired"`
+	Applied      bool   `json:"applied"`
+	Error        string `json:"error,omitempty"`
+}
+
+func (a *ReconcileAction) String() string {
+	current, desired := a.Current, a.Desired
+	if current == "" {
+		current = "none"
+	}
+	if desired == "" {
+		desired = "none"
+	}
+
+	return fmt.Sprintf("%s %s: %s -> %s", a.Action, a.Email, current, desired)
+}
+
+// PlanReconcile compares the desired state with live memberships and pending invitations and returns the changes.
+// Only emails passing the configured email domain filters are managed, the owner and the owner of the token
+// are left out entirely.
+func (c *Calendly) PlanReconcile(ctx context.Context, state *DesiredState) ([]*ReconcileAction, error) {
+	u, _, err := c.client.GetCurrentUser(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("calendly-connector: failed to get current user details: %w", err)
+	}
+
+	var rv []*ReconcileAction
+	seenOrgs := make(map[string]bool)
+	for _, org := range state.Organizations {
+		orgURI := org.Organization
+		if orgURI == "" {
+			orgURI = u.OrgURI
+		}
+
+		if seenOrgs[orgURI] {
+			return nil, fmt.Errorf("calendly-connector: organization %s listed more than once", orgURI)
+		}
+		seenOrgs[orgURI] = true
+
+		desired, err := desiredRoles(&org)
+		if err != nil {
+			return nil, err
+		}
+
+		actions, err := c.planOrganization(ctx, orgURI, desired, u.Email)
+		if err != nil {
+			return nil, err
+		}
+
+		rv = append(rv, actions...)
+	}
+
+	return rv, nil
+}
+
+// desiredRoles maps lowercase emails of the organization to their desired role.
+func desiredRoles(org *DesiredOrganization) (map[string]string, error) {
+	rv := make(map[string]string, len(org.Members))
+	for _, m := range org.Members {
+		email := strings.ToLower(strings.TrimSpace(m.Email))
+		if email == "" {
+			return nil, fmt.Errorf("calendly-connector: member without email in organization %s", org.Organization)
+		}
+
+		if !slices.Contains(DesiredRoles, m.Role) {
+			return nil, fmt.Errorf("calendly-connector: unknown role %s of %s, expected one of %s", m.Role, m.Email, strings.Join(DesiredRoles, ", "))
+		}
+
+		if _, ok := rv[email]; ok {
+			return nil, fmt.Errorf("calendly-connector: %s listed more than once", m.Email)
+		}
+
+		rv[email] = m.Role
+	}
+
+	return rv, nil
+}
+
+func (c *Calendly) planOrganization(ctx context.Context, orgURI string, desired map[string]string, tokenOwner string) ([]*ReconcileAction, error) {
+	live := newSnapshotStore(c.client, nil, c.config)
+
+	memberships, err := live.fetchMemberships(ctx, orgURI)
+	if err != nil {
+		return nil, fmt.Errorf("calendly-connector: failed to list users in org: %w", err)
+	}
+
+	invitations, err := live.fetchInvitations(ctx, orgURI, calendly.InvitationStatusPending)
+	if err != nil {
+		return nil, fmt.Errorf("calendly-connector: failed to list org invitations: %w", err)
+	}
+
+	// with SCIM, invited emails are provisioned as members right away
+	invitedRole := DesiredRolePending
+	if c.scim != nil {
+		invitedRole = DesiredRoleMember
+	}
+
+	return c.config.planChanges(orgURI, currentRoles(memberships, invitations), desired, invitedRole, tokenOwner), nil
+}
+
+// currentRoles maps lowercase emails of members and invited people to their role in the desired state terms.
+// Other roles like owner are kept as they are.
+func currentRoles(memberships []calendly.OrgMembership, invitations []calendly.Invitation) map[string]string {
+	rv := make(map[string]string)
+	for _, i := range invitations {
+		rv[strings.ToLower(i.Email)] = DesiredRolePending
+	}
+	for _, m := range memberships {
+		if m.User == nil {
+			continue
+		}
+
+		role := m.Role
+		if role == OrgUserEntitlement {
+			role = DesiredRoleMember
+		}
+		rv[strings.ToLower(m.User.Email)] = role
+	}
+
+	return rv
+}
+
+// planChanges returns actions moving the current roles to the desired ones. An invited email ends up
+// with the invitedRole, a desired admin role is reported as unsupported next to the invitation.
+// The tokenOwner email is skipped, removing it would revoke the token the connector runs with.
+func (c *Config) planChanges(orgURI string, current, desired map[string]string, invitedRole, tokenOwner string) []*ReconcileAction {
+	var emails []string
+	for email := range current {
+		emails = append(emails, email)
+	}
+	for email := range desired {
+		if _, ok := current[email]; !ok {
+			emails = append(emails, email)
+		}
+	}
+	slices.Sort(emails)
+
+	var rv []*ReconcileAction
+	for _, email := range emails {
+		if !c.includeEmail(email) || strings.EqualFold(email, tokenOwner) {
+			continue
+		}
+
+		from, to := current[email], desired[email]
+		if from == to || from == OrgOwnerEntitlement {
+			continue
+		}
+
+		action := &ReconcileAction{
+			Organization: orgURI,
+			Email:        email,
+			Current:      from,
+			Desired:      to,
+		}
+
+		switch {
+		case from == "":
+			action.Action = ReconcileInvite
+			rv = append(rv, action)
+
+			// admins have to be promoted in Calendly once they joined
+			if to == DesiredRoleAdmin {
+				rv = append(rv, &ReconcileAction{
+					Organization: orgURI,
+					Email:        email,
+					Action:       ReconcileUnsupported,
+					Current:      invitedRole,
+					Desired:      to,
+				})
+			}
+
+			continue
+		case to == "" && from == DesiredRolePending:
+			action.Action = ReconcileCancelInvitation
+		case to == "":
+			action.Action = ReconcileRemove
+		case from == DesiredRolePending && to != DesiredRolePending:
+			// the invitation has to be accepted, the member role is assigned by Calendly
+			if to == DesiredRoleMember {
+				continue
+			}
+			action.Action = ReconcileUnsupported
+		default:
+			// roles of members can't be changed through the API
+			action.Action = ReconcileUnsupported
+		}
+
+		rv = append(rv, action)
+	}
+
+	return rv
+}
+
+// ApplyReconcile performs the planned changes through the same code paths as granting and revoking
+// organization entitlements. Unsupported changes are left as they are.
+func (c *Calendly) ApplyReconcile(ctx context.Context, actions []*ReconcileAction) {
+	l := ctxzap.Extract(ctx)
+	ob := newOrgBuilder(c.client, c.scim, c.config, newSnapshotStore(c.client, c.scim, c.config))
+
+	for _, a := range actions {
+		if a.Action == ReconcileUnsupported {
+			continue
+		}
+
+		err := c.applyReconcileAction(ctx, ob, a)
+		if err != nil {
+			a.Error = err.Error()
+			l.Error(
+				"calendly-connector: failed to reconcile membership",
+				zap.String("organization", a.Organization),
+				zap.String("email", a.Email),
+				zap.String("action", a.Action),
+				zap.Error(err),
+			)
+
+			continue
+		}
+
+		a.Applied = true
+		l.Info(
+			"calendly-connector: reconciled membership",
+			zap.String("organization", a.Organization),
+			zap.String("email", a.Email),
+			zap.String("action", a.Action),
+		)
+	}
+}
+
+func (c *Calendly) applyReconcileAction(ctx context.Context, ob *orgBuilder, a *ReconcileAction) error {
+	orgID := &v2.ResourceId{ResourceType: orgResourceType.Id, Resource: a.Organization}
+	org := &v2.Resource{Id: orgID}
+
+	switch a.Action {
+	case ReconcileInvite:
+		principal, err := userInvitationResource(a.Email, orgID, 0)
+		if err != nil {
+			return err
+		}
+
+		// with SCIM, members are provisioned directly instead of invited
+		slug := OrgPendingUserEntitlement
+		if c.scim != nil && a.Desired != DesiredRolePending {
+			slug = OrgUserEntitlement
+		}
+
+		_, err = ob.Grant(ctx, principal, &v2.Entitlement{Resource: org, Slug: slug})
+
+		return err
+
+	case ReconcileCancelInvitation:
+		principal, err := userInvitationResource(a.Email, orgID, 0)
+		if err != nil {
+			return err
+		}
+
+		_, err = ob.Revoke(ctx, &v2.Grant{
+			Entitlement: &v2.Entitlement{Resource: org, Slug: OrgPendingUserEntitlement},
+			Principal:   principal,
+		})
+
+		return err
+
+	case ReconcileRemove:
+		// the principal is resolved to the membership by its email
+		principal, err := userInvitationResource(a.Email, orgID, 0)
+		if err != nil {
+			return err
+		}
+
+		_, err = ob.Revoke(ctx, &v2.Grant{
+			Entitlement: &v2.Entitlement{Resource: org, Slug: OrgUserEntitlement},
+			Principal:   principal,
+		})
+
+		return err
+	}
+
+	return fmt.Errorf("calendly-connector: unknown reconcile action %s", a.Action)
+}
@@ -0,0 +1,224 @@
+package connector
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/conductorone/baton-calendly/pkg/calendly"
+)
+
+func TestDesiredRoles(t *testing.T) {
+	tests := []struct {
+		name    string
+		members []DesiredMember
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name: "normalized emails",
+			members: []DesiredMember{
+				{Email: " Jane@Example.com ", Role: DesiredRoleAdmin},
+				{Email: "john@example.com", Role: DesiredRoleMember},
+				{Email: "new@example.com", Role: DesiredRolePending},
+			},
+			want: map[string]string{
+				"jane@example.com": DesiredRoleAdmin,
+				"john@example.com": DesiredRoleMember,
+				"new@example.com":  DesiredRolePending,
+			},
+		},
+		{
+			name:    "missing email",
+			members: []DesiredMember{{Email: " ", Role: DesiredRoleMember}},
+			wantErr: true,
+		},
+		{
+			name:    "unknown role",
+			members: []DesiredMember{{Email: "jane@example.com", Role: "owner"}},
+			wantErr: true,
+		},
+		{
+			name: "duplicate email",
+			members: []DesiredMember{
+				{Email: "jane@example.com", Role: DesiredRoleMember},
+				{Email: "JANE@example.com", Role: DesiredRoleAdmin},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := desiredRoles(&DesiredOrganization{Members: tt.members})
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("desiredRoles() = %v, want error", got)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("desiredRoles: %v", err)
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("desiredRoles() = %v, want %v", got, tt.want)
+			}
+			for email, role := range tt.want {
+				if got[email] != role {
+					t.Errorf("role of %s = %q, want %q", email, got[email], role)
+				}
+			}
+		})
+	}
+}
+
+func TestCurrentRoles(t *testing.T) {
+	memberships := []calendly.OrgMembership{
+		testMembership("owner", OrgOwnerEntitlement, "2024-01-01T00:00:00Z"),
+		testMembership("admin", OrgAdminEntitlement, "2024-01-01T00:00:00Z"),
+		testMembership("user", OrgUserEntitlement, "2024-01-01T00:00:00Z"),
+		{ID: "https://api.calendly.com/organization_memberships/orphan", Role: OrgUserEntitlement},
+	}
+	invitations := []calendly.Invitation{
+		{Email: "Invited@example.com"},
+		// an accepted invitation still listed as pending must not hide the membership
+		{Email: "user@example.com"},
+	}
+
+	got := currentRoles(memberships, invitations)
+	want := map[string]string{
+		"owner@example.com":   OrgOwnerEntitlement,
+		"admin@example.com":   DesiredRoleAdmin,
+		"user@example.com":    DesiredRoleMember,
+		"invited@example.com": DesiredRolePending,
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("currentRoles() = %v, want %v", got, want)
+	}
+	for email, role := range want {
+		if got[email] != role {
+			t.Errorf("role of %s = %q, want %q", email, got[email], role)
+		}
+	}
+}
+
+func TestPlanChanges(t *testing.T) {
+	const orgURI = "https://api.calendly.com/organizations/o"
+
+	tests := []struct {
+		name        string
+		config      *Config
+		current     map[string]string
+		desired     map[string]string
+		invitedRole string
+		tokenOwner  string
+		want        []string
+	}{
+		{
+			name:    "in sync",
+			config:  &Config{},
+			current: map[string]string{"a@example.com": DesiredRoleMember, "b@example.com": DesiredRolePending},
+			desired: map[string]string{"a@example.com": DesiredRoleMember, "b@example.com": DesiredRolePending},
+			want:    nil,
+		},
+		{
+			name:        "missing member is invited",
+			config:      &Config{},
+			current:     map[string]string{},
+			desired:     map[string]string{"a@example.com": DesiredRoleMember},
+			invitedRole: DesiredRolePending,
+			want:        []string{"invite a@example.com: none -> member"},
+		},
+		{
+			name:        "missing admin is invited and reported",
+			config:      &Config{},
+			current:     map[string]string{},
+			desired:     map[string]string{"a@example.com": DesiredRoleAdmin},
+			invitedRole: DesiredRolePending,
+			want: []string{
+				"invite a@example.com: none -> admin",
+				"unsupported a@example.com: pending -> admin",
+			},
+		},
+		{
+			name:        "missing admin provisioned through SCIM is reported",
+			config:      &Config{},
+			current:     map[string]string{},
+			desired:     map[string]string{"a@example.com": DesiredRoleAdmin},
+			invitedRole: DesiredRoleMember,
+			want: []string{
+				"invite a@example.com: none -> admin",
+				"unsupported a@example.com: member -> admin",
+			},
+		},
+		{
+			name:    "unlisted member and invitation",
+			config:  &Config{},
+			current: map[string]string{"a@example.com": DesiredRoleMember, "b@example.com": DesiredRolePending},
+			desired: map[string]string{},
+			want: []string{
+				"remove a@example.com: member -> none",
+				"cancel_invitation b@example.com: pending -> none",
+			},
+		},
+		{
+			name:    "role changes are unsupported",
+			config:  &Config{},
+			current: map[string]string{"a@example.com": DesiredRoleMember, "b@example.com": DesiredRolePending},
+			desired: map[string]string{"a@example.com": DesiredRoleAdmin, "b@example.com": DesiredRoleAdmin},
+			want: []string{
+				"unsupported a@example.com: member -> admin",
+				"unsupported b@example.com: pending -> admin",
+			},
+		},
+		{
+			name:    "pending member waits for acceptance",
+			config:  &Config{},
+			current: map[string]string{"a@example.com": DesiredRolePending},
+			desired: map[string]string{"a@example.com": DesiredRoleMember},
+			want:    nil,
+		},
+		{
+			name:    "owner is never changed",
+			config:  &Config{},
+			current: map[string]string{"a@example.com": OrgOwnerEntitlement},
+			desired: map[string]string{},
+			want:    nil,
+		},
+		{
+			name:       "token owner is never changed",
+			config:     &Config{},
+			current:    map[string]string{"a@example.com": DesiredRoleAdmin, "b@example.com": DesiredRoleMember},
+			desired:    map[string]string{},
+			tokenOwner: "A@example.com",
+			want:       []string{"remove b@example.com: member -> none"},
+		},
+		{
+			name:    "filtered emails are not managed",
+			config:  &Config{ExcludeEmailDomains: []string{"contractor.com"}},
+			current: map[string]string{"a@contractor.com": DesiredRoleMember},
+			desired: map[string]string{"b@contractor.com": DesiredRoleMember},
+			want:    nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actions := tt.config.planChanges(orgURI, tt.current, tt.desired, tt.invitedRole, tt.tokenOwner)
+
+			var got []string
+			for _, a := range actions {
+				if a.Organization != orgURI {
+					t.Errorf("organization of %s = %q, want %q", a, a.Organization, orgURI)
+				}
+				got = append(got, a.String())
+			}
+
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("planChanges() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
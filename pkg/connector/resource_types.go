@@ -17,4 +17,26 @@ var (
 		Id:          "org",
 		DisplayName: "Organization",
 	}
+
+	groupResourceType = &v2.ResourceType{
+		Id:          "group",
+		DisplayName: "Group",
+		Traits:      []v2.ResourceType_Trait{v2.ResourceType_TRAIT_GROUP},
+	}
+
+	eventTypeResourceType = &v2.ResourceType{
+		Id:          "event_type",
+		DisplayName: "Event Type",
+	}
+
+	routingFormResourceType = &v2.ResourceType{
+		Id:          "routing_form",
+		DisplayName: "Routing Form",
+		Traits:      []v2.ResourceType_Trait{v2.ResourceType_TRAIT_APP},
+	}
+
+	webhookSubscriptionResourceType = &v2.ResourceType{
+		Id:          "webhook_subscription",
+		DisplayName: "Webhook Subscription",
+	}
 )
@@ -0,0 +1,146 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/conductorone/baton-calendly/pkg/calendly"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	ent "github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/conductorone/baton-sdk/pkg/types/grant"
+	rs "github.com/conductorone/baton-sdk/pkg/types/resource"
+)
+
+const RoutingFormManagerEntitlement = "manager"
+
+// RoutingFormManagerRoles are the organization roles allowed to manage routing forms.
+// Calendly does not expose owners of routing forms, only these roles are known to have access.
+var RoutingFormManagerRoles = []string{
+	OrgAdminEntitlement,
+	OrgOwnerEntitlement,
+}
+
+type routingFormBuilder struct {
+	client       *calendly.Client
+	resourceType *v2.ResourceType
+	config       *Config
+}
+
+func (r *routingFormBuilder) ResourceType(ctx context.Context) *v2.ResourceType {
+	return routingFormResourceType
+}
+
+// routingFormResource creates routing form resource. Routing forms are public facing like apps, so the
+// profile with the status and question count is kept in the app trait and summarized in the description.
+// Routing forms aren't linked to event types, the API exposes neither the routing rules nor their
+// destinations, only individual submissions name the event type they were routed to.
+func routingFormResource(form *calendly.RoutingForm, parentID *v2.ResourceId) (*v2.Resource, error) {
+	profile := map[string]interface{}{
+		"status":         form.Status,
+		"question_count": len(form.Questions),
+		"created_at":     form.CreatedAt,
+		"updated_at":     form.UpdatedAt,
+	}
+
+	resource, err := rs.NewAppResource(
+		form.Name,
+		routingFormResourceType,
+		form.ID,
+		[]rs.AppTraitOption{rs.WithAppProfile(profile)},
+		rs.WithParentResourceID(parentID),
+		rs.WithDescription(fmt.Sprintf("%s routing form with %d questions", form.Status, len(form.Questions))),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return resource, nil
+}
+
+// List returns all routing forms under the organization.
+func (r *routingFormBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId, pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	if parentResourceID == nil {
+		return nil, "", nil, nil
+	}
+
+	bag, page, err := parsePageToken(pToken.Token, &v2.ResourceId{ResourceType: routingFormResourceType.Id})
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	if err := r.config.waitForRateLimit(ctx, r.client); err != nil {
+		return nil, "", nil, err
+	}
+
+	pgVars := calendly.NewPaginationVars(r.config.pageSize(r.client), page)
+	forms, nextPage, rlf, err := r.client.ListRoutingForms(ctx, parentResourceID.Resource, pgVars)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("calendly-connector: failed to list routing forms: %w", err)
+	}
+
+	var rv []*v2.Resource
+	for _, f := range forms {
+		fr, err := routingFormResource(&f, parentResourceID)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("calendly-connector: failed to create routing form resource: %w", err)
+		}
+
+		rv = append(rv, fr)
+	}
+
+	next, err := bag.NextToken(nextPage)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	return rv, next, WithRateLimitAnnotations(rlf), nil
+}
+
+// Entitlements returns the manager entitlement for the routing form.
+func (r *routingFormBuilder) Entitlements(ctx context.Context, resource *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	managerOptions := []ent.EntitlementOption{
+		ent.WithGrantableTo(userResourceType),
+		ent.WithDisplayName(fmt.Sprintf("%s manager", resource.DisplayName)),
+		ent.WithDescription(fmt.Sprintf("Manages %s routing form as an organization admin or owner", resource.DisplayName)),
+	}
+
+	return []*v2.Entitlement{
+		ent.NewPermissionEntitlement(resource, RoutingFormManagerEntitlement, managerOptions...),
+	}, "", nil, nil
+}
+
+// Grants returns manager grant for the routing form, expanded to every organization member
+// holding one of the roles that can manage routing forms.
+func (r *routingFormBuilder) Grants(ctx context.Context, resource *v2.Resource, _ *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	if resource.ParentResourceId == nil {
+		return nil, "", nil, nil
+	}
+
+	org := &v2.Resource{Id: resource.ParentResourceId}
+
+	var entitlementIDs []string
+	for _, role := range RoutingFormManagerRoles {
+		entitlementIDs = append(entitlementIDs, ent.NewEntitlementID(org, role))
+	}
+
+	return []*v2.Grant{
+		grant.NewGrant(
+			resource,
+			RoutingFormManagerEntitlement,
+			org.Id,
+			grant.WithAnnotation(&v2.GrantExpandable{
+				EntitlementIds: entitlementIDs,
+			}),
+		),
+	}, "", nil, nil
+}
+
+func newRoutingFormBuilder(client *calendly.Client, config *Config) *routingFormBuilder {
+	return &routingFormBuilder{
+		client:       client,
+		resourceType: routingFormResourceType,
+		config:       config,
+	}
+}
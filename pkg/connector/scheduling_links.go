@@ -0,0 +1,46 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// CreateSchedulingLink creates a single-use, or limited, booking link for the event type and returns its URL.
+// The event type is identified by its resource ID, the event type URI.
+func (c *Calendly) CreateSchedulingLink(ctx context.Context, eventTypeID string, maxEventCount int) (string, error) {
+	l := ctxzap.Extract(ctx)
+
+	if !isURI(eventTypeID) {
+		return "", status.Errorf(codes.InvalidArgument, "calendly-connector: invalid event type resource id %s", eventTypeID)
+	}
+
+	if maxEventCount < 1 {
+		return "", status.Error(codes.InvalidArgument, "calendly-connector: max event count must be at least 1")
+	}
+
+	link, _, err := c.client.CreateSchedulingLink(ctx, eventTypeID, maxEventCount)
+	if err != nil {
+		l.Error(
+			"calendly-connector: failed to create scheduling link",
+			zap.String("event_type", eventTypeID),
+			zap.Int("max_event_count", maxEventCount),
+			zap.Error(err),
+		)
+
+		return "", fmt.Errorf("calendly-connector: failed to create scheduling link: %w", err)
+	}
+
+	l.Info(
+		"calendly-connector: created scheduling link",
+		zap.String("event_type", eventTypeID),
+		zap.Int("max_event_count", maxEventCount),
+		zap.String("booking_url", link.BookingURL),
+	)
+
+	return link.BookingURL, nil
+}
@@ -0,0 +1,227 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/conductorone/baton-calendly/pkg/calendly"
+	"github.com/conductorone/baton-calendly/pkg/scim"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/connectorbuilder"
+	rs "github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// scimStatus maps the SCIM active flag of the user to the user trait status.
+// Users unknown to SCIM are enabled, as are all members when SCIM is disabled.
+func scimStatus(user *scim.User) v2.UserTrait_Status_Status {
+	if user != nil && !user.Active {
+		return v2.UserTrait_Status_STATUS_DISABLED
+	}
+
+	return v2.UserTrait_Status_STATUS_ENABLED
+}
+
+// scimUserIDPrefix marks resource IDs of SCIM users, so they don't collide with emails of pending users
+// or URIs of members.
+const scimUserIDPrefix = "scim:"
+
+// scimUserResource creates user resource of a SCIM user who is not a member of the organization, e.g. a deactivated one.
+// It is identified by its SCIM ID and carries the email in its trait, so it can be reactivated by a grant.
+func scimUserResource(user *scim.User, parentID *v2.ResourceId) (*v2.Resource, error) {
+	email := user.PrimaryEmail()
+	profile := map[string]interface{}{
+		"email":   email,
+		"scim_id": user.ID,
+	}
+
+	displayName := email
+	if user.Name != nil {
+		profile["firstName"] = user.Name.GivenName
+		profile["lastName"] = user.Name.FamilyName
+		if name := strings.TrimSpace(user.Name.GivenName + " " + user.Name.FamilyName); name != "" {
+			displayName = name
+		}
+	}
+
+	resource, err := rs.NewUserResource(
+		displayName,
+		userResourceType,
+		scimUserIDPrefix+user.ID,
+		[]rs.UserTraitOption{
+			rs.WithUserProfile(profile),
+			rs.WithEmail(email, true),
+			rs.WithUserLogin(email),
+			rs.WithStatus(scimStatus(user)),
+		},
+		rs.WithParentResourceID(parentID),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create SCIM user resource: %w", err)
+	}
+
+	return resource, nil
+}
+
+// scimUserFromProfile builds SCIM user with the email and the name from the user profile.
+func scimUserFromProfile(email string, profile map[string]interface{}) *scim.User {
+	user := &scim.User{
+		UserName: email,
+		Emails: []scim.Email{
+			{Value: email, Type: "work", Primary: true},
+		},
+		Active: true,
+	}
+
+	firstName, _ := profile["first_name"].(string)
+	if firstName == "" {
+		firstName, _ = profile["firstName"].(string)
+	}
+
+	lastName, _ := profile["last_name"].(string)
+	if lastName == "" {
+		lastName, _ = profile["lastName"].(string)
+	}
+
+	if firstName != "" || lastName != "" {
+		user.Name = &scim.Name{
+			GivenName:  firstName,
+			FamilyName: lastName,
+		}
+	}
+
+	return user
+}
+
+// provisionSCIMUser reactivates the SCIM user with the principal's email or creates one when there is none.
+func (o *orgBuilder) provisionSCIMUser(ctx context.Context, principal *v2.Resource) (annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	email := principalEmail(principal)
+	if email == "" {
+		return nil, status.Error(codes.InvalidArgument, "calendly-connector: missing email of the user")
+	}
+
+	user, err := o.scim.FindUserByEmail(ctx, email)
+	if err != nil {
+		return nil, fmt.Errorf("calendly-connector: failed to find SCIM user: %w", err)
+	}
+
+	if user != nil && user.Active {
+		return annotations.New(&v2.GrantAlreadyExists{}), nil
+	}
+
+	if user != nil {
+		err = o.scim.SetActive(ctx, user.ID, true)
+		if err != nil {
+			return nil, fmt.Errorf("calendly-connector: failed to reactivate SCIM user: %w", err)
+		}
+
+		l.Info("calendly-connector: reactivated SCIM user", zap.String("email", email), zap.String("scim_id", user.ID))
+
+		return nil, nil
+	}
+
+	var profile map[string]interface{}
+	if ut, err := rs.GetUserTrait(principal); err == nil && ut.Profile != nil {
+		profile = ut.Profile.AsMap()
+	}
+
+	user, err = o.scim.CreateUser(ctx, scimUserFromProfile(email, profile))
+	if err != nil {
+		return nil, fmt.Errorf("calendly-connector: failed to create SCIM user: %w", err)
+	}
+
+	l.Info("calendly-connector: created SCIM user", zap.String("email", email), zap.String("scim_id", user.ID))
+
+	return nil, nil
+}
+
+// deactivateSCIMUser deactivates the SCIM user with the email. It reports false when there is no such user,
+// e.g. a member who joined through an invitation, who must be removed through the Calendly API instead.
+func (o *orgBuilder) deactivateSCIMUser(ctx context.Context, email string) (bool, error) {
+	user, err := o.scim.FindUserByEmail(ctx, email)
+	if err != nil {
+		return false, fmt.Errorf("calendly-connector: failed to find SCIM user: %w", err)
+	}
+
+	if user == nil {
+		return false, nil
+	}
+
+	if user.Active {
+		err = o.scim.SetActive(ctx, user.ID, false)
+		if err != nil {
+			return false, fmt.Errorf("calendly-connector: failed to deactivate SCIM user: %w", err)
+		}
+	}
+
+	ctxzap.Extract(ctx).Info("calendly-connector: deactivated SCIM user", zap.String("email", email), zap.String("scim_id", user.ID))
+
+	return true, nil
+}
+
+// CreateAccount provisions the user into the organization through SCIM. It requires SCIM to be enabled.
+func (o *userBuilder) CreateAccount(
+	ctx context.Context,
+	accountInfo *v2.AccountInfo,
+	credentialOptions *v2.CredentialOptions,
+) (connectorbuilder.CreateAccountResponse, []*v2.PlaintextData, annotations.Annotations, error) {
+	if o.scim == nil {
+		return nil, nil, nil, status.Error(codes.FailedPrecondition, "calendly-connector: SCIM must be enabled to create accounts")
+	}
+
+	email := accountInfo.GetLogin()
+	for _, e := range accountInfo.GetEmails() {
+		if e.GetIsPrimary() || email == "" {
+			email = e.GetAddress()
+		}
+	}
+
+	if email == "" {
+		return nil, nil, nil, status.Error(codes.InvalidArgument, "calendly-connector: missing email of the account")
+	}
+
+	user, err := o.scim.CreateUser(ctx, scimUserFromProfile(email, accountInfo.GetProfile().AsMap()))
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("calendly-connector: failed to create SCIM user: %w", err)
+	}
+
+	ctxzap.Extract(ctx).Info("calendly-connector: created SCIM user", zap.String("email", email), zap.String("scim_id", user.ID))
+
+	resource, err := o.accountResource(ctx, email, user)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return &v2.CreateAccountResponse_SuccessResult{
+		Resource: resource,
+	}, nil, nil, nil
+}
+
+// accountResource returns the user resource of the created account. The Calendly user is used when
+// the membership is already visible, so the resource matches the one emitted by the next sync.
+func (o *userBuilder) accountResource(ctx context.Context, email string, user *scim.User) (*v2.Resource, error) {
+	u, _, err := o.client.GetCurrentUser(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("calendly-connector: failed to get current user details: %w", err)
+	}
+
+	orgID := &v2.ResourceId{ResourceType: orgResourceType.Id, Resource: u.OrgURI}
+
+	memberships, _, err := o.client.ListUsersUnderOrg(ctx, u.OrgURI, nil, calendly.NewFilterVars(email))
+	if err != nil {
+		return nil, fmt.Errorf("calendly-connector: failed to list users in org: %w", err)
+	}
+
+	if len(memberships) == 1 && memberships[0].User != nil {
+		return userResource(memberships[0].User, orgID, nil, scimStatus(user))
+	}
+
+	return scimUserResource(user, orgID)
+}
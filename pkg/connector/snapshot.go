@@ -0,0 +1,407 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/conductorone/baton-calendly/pkg/calendly"
+	"github.com/conductorone/baton-calendly/pkg/scim"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+)
+
+// orgSnapshot holds memberships and pending invitations of an organization fetched once per sync.
+// Invitations hold a single invitation per email of someone who isn't a member yet, the number of
+// pending invitations of each email is kept in pendingInvitations. With invitation history enabled,
+// accepted invitations are keyed by the user URI and declined ones by lowercase email, since a declined
+// invitation isn't linked to a user. With SCIM enabled it also holds SCIM users keyed by lowercase email.
+type orgSnapshot struct {
+	memberships         []calendly.OrgMembership
+	invitations         []calendly.Invitation
+	pendingInvitations  map[string]int
+	acceptedInvitations map[string]*calendly.Invitation
+	declinedInvitations map[string][]*calendly.Invitation
+	scimUsers           map[string]*scim.User
+}
+
+type snapshotEntry struct {
+	once     sync.Once
+	snapshot *orgSnapshot
+	err      error
+}
+
+// snapshotStore shares organization snapshots between the user, organization and event type builders,
+// so both the user list and the grant list are served from a single pass over the API.
+type snapshotStore struct {
+	client *calendly.Client
+	scim   *scim.Client
+	config *Config
+
+	mu      sync.Mutex
+	entries map[string]*snapshotEntry
+}
+
+func newSnapshotStore(client *calendly.Client, scimClient *scim.Client, config *Config) *snapshotStore {
+	return &snapshotStore{
+		client:  client,
+		scim:    scimClient,
+		config:  config,
+		entries: make(map[string]*snapshotEntry),
+	}
+}
+
+// reset drops all snapshots. It is called when a new sync starts listing organizations.
+func (s *snapshotStore) reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = make(map[string]*snapshotEntry)
+}
+
+// get returns snapshot of the organization, fetching it on first use.
+func (s *snapshotStore) get(ctx context.Context, orgURI string) (*orgSnapshot, error) {
+	s.mu.Lock()
+	entry, ok := s.entries[orgURI]
+	if !ok {
+		entry = &snapshotEntry{}
+		s.entries[orgURI] = entry
+	}
+	s.mu.Unlock()
+
+	entry.once.Do(func() {
+		entry.snapshot, entry.err = s.fetch(ctx, orgURI)
+	})
+
+	if entry.err != nil {
+		// allow the next call to retry the fetch
+		s.mu.Lock()
+		if s.entries[orgURI] == entry {
+			delete(s.entries, orgURI)
+		}
+		s.mu.Unlock()
+	}
+
+	return entry.snapshot, entry.err
+}
+
+// fetch walks memberships and pending invitations of the organization concurrently.
+func (s *snapshotStore) fetch(ctx context.Context, orgURI string) (*orgSnapshot, error) {
+	var (
+		wg                                                        sync.WaitGroup
+		snapshot                                                  orgSnapshot
+		accepted, declined                                        []calendly.Invitation
+		membershipsErr, invErr, acceptedErr, declinedErr, scimErr error
+	)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		snapshot.memberships, membershipsErr = s.fetchMemberships(ctx, orgURI)
+	}()
+
+	if s.config.includeInvitations() {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			snapshot.invitations, invErr = s.fetchInvitations(ctx, orgURI, calendly.InvitationStatusPending)
+		}()
+	}
+
+	if s.config.invitationHistory() {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			accepted, acceptedErr = s.fetchInvitations(ctx, orgURI, calendly.InvitationStatusAccepted)
+		}()
+		go func() {
+			defer wg.Done()
+			declined, declinedErr = s.fetchInvitations(ctx, orgURI, calendly.InvitationStatusDeclined)
+		}()
+	}
+
+	if s.scim != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			snapshot.scimUsers, scimErr = s.fetchSCIMUsers(ctx)
+		}()
+	}
+
+	wg.Wait()
+
+	if membershipsErr != nil {
+		return nil, fmt.Errorf("calendly-connector: failed to list users in org: %w", membershipsErr)
+	}
+
+	if invErr != nil {
+		return nil, fmt.Errorf("calendly-connector: failed to list org invitations: %w", invErr)
+	}
+
+	if scimErr != nil {
+		return nil, fmt.Errorf("calendly-connector: failed to list SCIM users: %w", scimErr)
+	}
+
+	snapshot.invitations, snapshot.pendingInvitations = dedupeInvitations(ctx, orgURI, snapshot.memberships, snapshot.invitations)
+
+	// the history only adds grant metadata, the sync goes on without it
+	if acceptedErr != nil || declinedErr != nil {
+		ctxzap.Extract(ctx).Warn(
+			"calendly-connector: failed to list invitation history, grants are synced without it",
+			zap.String("org", orgURI),
+			zap.NamedError("accepted_error", acceptedErr),
+			zap.NamedError("declined_error", declinedErr),
+		)
+	} else {
+		snapshot.acceptedInvitations = acceptedInvitations(accepted)
+		snapshot.declinedInvitations = declinedInvitations(declined)
+	}
+
+	return &snapshot, nil
+}
+
+// dedupeInvitations keeps the oldest pending invitation of each email and drops invitations of emails
+// that already belong to a member, so the same person doesn't get conflicting grants. Both cases are logged.
+func dedupeInvitations(ctx context.Context, orgURI string, memberships []calendly.OrgMembership, invitations []calendly.Invitation) ([]calendly.Invitation, map[string]int) {
+	l := ctxzap.Extract(ctx)
+
+	members := make(map[string]bool, len(memberships))
+	for _, m := range memberships {
+		if m.User != nil {
+			members[strings.ToLower(m.User.Email)] = true
+		}
+	}
+
+	pending := make(map[string]int, len(invitations))
+	for _, i := range invitations {
+		pending[strings.ToLower(i.Email)]++
+	}
+
+	sorted := slices.Clone(invitations)
+	slices.SortStableFunc(sorted, func(a, b calendly.Invitation) int {
+		return strings.Compare(a.CreatedAt, b.CreatedAt)
+	})
+
+	rv := make([]calendly.Invitation, 0, len(pending))
+	seen := make(map[string]bool, len(pending))
+	for _, i := range sorted {
+		email := strings.ToLower(i.Email)
+		if seen[email] {
+			continue
+		}
+		seen[email] = true
+
+		if members[email] {
+			l.Warn(
+				"calendly-connector: member has pending invitations, skipping them",
+				zap.String("org", orgURI),
+				zap.String("email", i.Email),
+				zap.Int("pending_invitations", pending[email]),
+			)
+
+			continue
+		}
+
+		if pending[email] > 1 {
+			l.Warn(
+				"calendly-connector: duplicate pending invitations",
+				zap.String("org", orgURI),
+				zap.String("email", i.Email),
+				zap.Int("pending_invitations", pending[email]),
+			)
+		}
+
+		rv = append(rv, i)
+	}
+
+	return rv, pending
+}
+
+func (s *snapshotStore) fetchMemberships(ctx context.Context, orgURI string) ([]calendly.OrgMembership, error) {
+	var rv []calendly.OrgMembership
+	page := ""
+	for {
+		if err := s.config.waitForRateLimit(ctx, s.client); err != nil {
+			return nil, err
+		}
+
+		memberships, nextPage, err := s.client.ListUsersUnderOrg(ctx, orgURI, calendly.NewPaginationVars(s.config.pageSize(s.client), page), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		rv = append(rv, memberships...)
+		if nextPage == "" {
+			return rv, nil
+		}
+		page = nextPage
+	}
+}
+
+func (s *snapshotStore) fetchInvitations(ctx context.Context, orgURI, status string) ([]calendly.Invitation, error) {
+	var rv []calendly.Invitation
+	page := ""
+	for {
+		if err := s.config.waitForRateLimit(ctx, s.client); err != nil {
+			return nil, err
+		}
+
+		invitations, nextPage, _, err := s.client.ListUserInvitations(ctx, orgURI, status, calendly.NewPaginationVars(s.config.pageSize(s.client), page), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		rv = append(rv, invitations...)
+		if nextPage == "" {
+			return rv, nil
+		}
+		page = nextPage
+	}
+}
+
+func (s *snapshotStore) fetchSCIMUsers(ctx context.Context) (map[string]*scim.User, error) {
+	rv := make(map[string]*scim.User)
+	start := 1
+	for {
+		users, total, err := s.scim.ListUsers(ctx, start, s.config.pageSize(nil))
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range users {
+			rv[strings.ToLower(users[i].PrimaryEmail())] = &users[i]
+		}
+
+		start += len(users)
+		if len(users) == 0 || start > total {
+			return rv, nil
+		}
+	}
+}
+
+// acceptedInvitations maps accepted invitations by the user who accepted them, keeping the latest one.
+func acceptedInvitations(invitations []calendly.Invitation) map[string]*calendly.Invitation {
+	rv := make(map[string]*calendly.Invitation, len(invitations))
+	for i := range invitations {
+		inv := &invitations[i]
+		if inv.UserID == "" {
+			continue
+		}
+
+		if prev, ok := rv[inv.UserID]; ok && prev.UpdatedAt > inv.UpdatedAt {
+			continue
+		}
+
+		rv[inv.UserID] = inv
+	}
+
+	return rv
+}
+
+// declinedInvitations groups declined invitations by lowercase email, the latest one first.
+func declinedInvitations(invitations []calendly.Invitation) map[string][]*calendly.Invitation {
+	rv := make(map[string][]*calendly.Invitation)
+	for i := range invitations {
+		email := strings.ToLower(invitations[i].Email)
+		rv[email] = append(rv[email], &invitations[i])
+	}
+
+	for _, declined := range rv {
+		slices.SortStableFunc(declined, func(a, b *calendly.Invitation) int {
+			return strings.Compare(b.UpdatedAt, a.UpdatedAt)
+		})
+	}
+
+	return rv
+}
+
+// invitationMetadata returns grant metadata describing how the member was onboarded and
+// the invitations the member declined before, nil when there is neither.
+func (o *orgSnapshot) invitationMetadata(user *calendly.User) map[string]interface{} {
+	rv := make(map[string]interface{})
+
+	if inv, ok := o.acceptedInvitations[user.ID]; ok {
+		rv["invitation_uri"] = inv.ID
+		rv["invitation_created_at"] = inv.CreatedAt
+		rv["invitation_last_sent_at"] = inv.LastSentAt
+		rv["invitation_accepted_at"] = inv.UpdatedAt
+		rv["invitation_user"] = inv.UserID
+	}
+
+	if declined := o.declinedInvitations[strings.ToLower(user.Email)]; len(declined) > 0 {
+		rv["declined_invitations"] = len(declined)
+		rv["last_declined_invitation_uri"] = declined[0].ID
+		rv["last_declined_at"] = declined[0].UpdatedAt
+	}
+
+	if len(rv) == 0 {
+		return nil
+	}
+
+	return rv
+}
+
+// membership returns membership of the user in the organization, nil when the user isn't a member.
+func (o *orgSnapshot) membership(userURI string) *calendly.OrgMembership {
+	i := slices.IndexFunc(o.memberships, func(m calendly.OrgMembership) bool {
+		return m.User != nil && m.User.ID == userURI
+	})
+	if i == -1 {
+		return nil
+	}
+
+	return &o.memberships[i]
+}
+
+// scimUser returns the SCIM user with the email, nil when SCIM is disabled or there is no such user.
+func (o *orgSnapshot) scimUser(email string) *scim.User {
+	return o.scimUsers[strings.ToLower(email)]
+}
+
+// deactivatedUsers returns SCIM users that are deactivated and no longer members of the organization.
+func (o *orgSnapshot) deactivatedUsers() []*scim.User {
+	members := make(map[string]bool, len(o.memberships))
+	for _, m := range o.memberships {
+		if m.User != nil {
+			members[strings.ToLower(m.User.Email)] = true
+		}
+	}
+
+	var rv []*scim.User
+	for email, u := range o.scimUsers {
+		if !u.Active && !members[email] {
+			rv = append(rv, u)
+		}
+	}
+
+	slices.SortFunc(rv, func(a, b *scim.User) int {
+		return strings.Compare(a.UserName, b.UserName)
+	})
+
+	return rv
+}
+
+// pageOf returns a page of the snapshot items, the page token being the offset of the first item.
+func pageOf[T any](items []T, page string, size int) ([]T, string, error) {
+	offset := 0
+	if page != "" {
+		var err error
+		offset, err = strconv.Atoi(page)
+		if err != nil || offset < 0 {
+			return nil, "", fmt.Errorf("calendly-connector: invalid page token %s", page)
+		}
+	}
+
+	if offset >= len(items) {
+		return nil, "", nil
+	}
+
+	end := min(offset+size, len(items))
+	if end == len(items) {
+		return items[offset:end], "", nil
+	}
+
+	return items[offset:end], strconv.Itoa(end), nil
+}
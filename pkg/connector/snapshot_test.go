@@ -0,0 +1,167 @@
+package connector
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/conductorone/baton-calendly/pkg/calendly"
+)
+
+func TestDedupeInvitations(t *testing.T) {
+	memberships := []calendly.OrgMembership{
+		testMembership("member", OrgUserEntitlement, "2024-01-01T00:00:00Z"),
+	}
+
+	tests := []struct {
+		name        string
+		invitations []calendly.Invitation
+		want        []string
+		wantPending map[string]int
+	}{
+		{
+			name:        "no invitations",
+			invitations: nil,
+			want:        nil,
+			wantPending: map[string]int{},
+		},
+		{
+			name: "oldest duplicate kept",
+			invitations: []calendly.Invitation{
+				{ID: "newer", Email: "a@example.com", CreatedAt: "2024-02-01T00:00:00Z"},
+				{ID: "b", Email: "b@example.com", CreatedAt: "2024-01-15T00:00:00Z"},
+				{ID: "oldest", Email: "A@Example.com", CreatedAt: "2024-01-01T00:00:00Z"},
+			},
+			want:        []string{"oldest", "b"},
+			wantPending: map[string]int{"a@example.com": 2, "b@example.com": 1},
+		},
+		{
+			name: "invitations of members dropped",
+			invitations: []calendly.Invitation{
+				{ID: "member", Email: "Member@example.com", CreatedAt: "2024-01-01T00:00:00Z"},
+				{ID: "a", Email: "a@example.com", CreatedAt: "2024-01-02T00:00:00Z"},
+			},
+			want:        []string{"a"},
+			wantPending: map[string]int{"member@example.com": 1, "a@example.com": 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, pending := dedupeInvitations(context.Background(), "org", memberships, tt.invitations)
+
+			var ids []string
+			for _, i := range got {
+				ids = append(ids, i.ID)
+			}
+
+			if !slices.Equal(ids, tt.want) {
+				t.Errorf("invitations = %v, want %v", ids, tt.want)
+			}
+
+			if !maps.Equal(pending, tt.wantPending) {
+				t.Errorf("pending = %v, want %v", pending, tt.wantPending)
+			}
+		})
+	}
+}
+
+func TestAcceptedInvitations(t *testing.T) {
+	invitations := []calendly.Invitation{
+		{ID: "old", UserID: "users/a", UpdatedAt: "2024-01-01T00:00:00Z"},
+		{ID: "latest", UserID: "users/a", UpdatedAt: "2024-03-01T00:00:00Z"},
+		{ID: "between", UserID: "users/a", UpdatedAt: "2024-02-01T00:00:00Z"},
+		{ID: "other", UserID: "users/b", UpdatedAt: "2024-01-01T00:00:00Z"},
+		{ID: "no user", UpdatedAt: "2024-04-01T00:00:00Z"},
+	}
+
+	got := acceptedInvitations(invitations)
+
+	tests := []struct {
+		userURI string
+		want    string
+	}{
+		{userURI: "users/a", want: "latest"},
+		{userURI: "users/b", want: "other"},
+		{userURI: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.userURI, func(t *testing.T) {
+			inv, ok := got[tt.userURI]
+			if tt.want == "" {
+				if ok {
+					t.Errorf("got invitation %q, want none", inv.ID)
+				}
+				return
+			}
+
+			if !ok || inv.ID != tt.want {
+				t.Errorf("got %+v, want invitation %q", inv, tt.want)
+			}
+		})
+	}
+}
+
+func TestInvitationMetadata(t *testing.T) {
+	snapshot := &orgSnapshot{
+		acceptedInvitations: acceptedInvitations([]calendly.Invitation{
+			{ID: "accepted", UserID: "users/a", CreatedAt: "2024-01-01T00:00:00Z", UpdatedAt: "2024-01-02T00:00:00Z"},
+		}),
+		declinedInvitations: declinedInvitations([]calendly.Invitation{
+			{ID: "declined-1", Email: "a@example.com", UpdatedAt: "2023-01-01T00:00:00Z"},
+			{ID: "declined-2", Email: "A@example.com", UpdatedAt: "2023-06-01T00:00:00Z"},
+			{ID: "declined-3", Email: "c@example.com", UpdatedAt: "2023-02-01T00:00:00Z"},
+		}),
+	}
+
+	tests := []struct {
+		name string
+		user *calendly.User
+		want map[string]interface{}
+	}{
+		{
+			name: "accepted after declining",
+			user: &calendly.User{ID: "users/a", Email: "a@example.com"},
+			want: map[string]interface{}{
+				"invitation_uri":               "accepted",
+				"invitation_accepted_at":       "2024-01-02T00:00:00Z",
+				"declined_invitations":         2,
+				"last_declined_invitation_uri": "declined-2",
+				"last_declined_at":             "2023-06-01T00:00:00Z",
+			},
+		},
+		{
+			name: "declined only",
+			user: &calendly.User{ID: "users/c", Email: "c@example.com"},
+			want: map[string]interface{}{
+				"declined_invitations":         1,
+				"last_declined_invitation_uri": "declined-3",
+			},
+		},
+		{
+			name: "no invitations",
+			user: &calendly.User{ID: "users/b", Email: "b@example.com"},
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := snapshot.invitationMetadata(tt.user)
+			if tt.want == nil {
+				if got != nil {
+					t.Errorf("invitationMetadata() = %v, want nil", got)
+				}
+				return
+			}
+
+			for k, v := range tt.want {
+				if got[k] != v {
+					t.Errorf("%s = %v, want %v", k, got[k], v)
+				}
+			}
+		})
+	}
+}
@@ -3,9 +3,11 @@ package connector
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/conductorone/baton-calendly/pkg/calendly"
+	"github.com/conductorone/baton-calendly/pkg/scim"
 	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
 	"github.com/conductorone/baton-sdk/pkg/annotations"
 	"github.com/conductorone/baton-sdk/pkg/helpers"
@@ -15,14 +17,24 @@ import (
 
 type userBuilder struct {
 	client       *calendly.Client
+	scim         *scim.Client
 	resourceType *v2.ResourceType
+	config       *Config
+	snapshots    *snapshotStore
 }
 
-func userInvitationResource(email string, parentID *v2.ResourceId) (*v2.Resource, error) {
+// userInvitationResource creates user resource of an invited person. More than one pending
+// invitation of the email is flagged in the profile.
+func userInvitationResource(email string, parentID *v2.ResourceId, pendingInvitations int) (*v2.Resource, error) {
 	profile := map[string]interface{}{
 		"email": email,
 	}
 
+	if pendingInvitations > 1 {
+		profile["pending_invitations"] = pendingInvitations
+		profile["duplicate_invitations"] = true
+	}
+
 	invitationOptions := []rs.UserTraitOption{
 		rs.WithUserProfile(profile),
 		rs.WithEmail(email, true),
@@ -43,7 +55,7 @@ func userInvitationResource(email string, parentID *v2.ResourceId) (*v2.Resource
 	return resource, nil
 }
 
-func userResource(user *calendly.User, parentId *v2.ResourceId) (*v2.Resource, error) {
+func userResource(user *calendly.User, parentId *v2.ResourceId, lastActivity *time.Time, userStatus v2.UserTrait_Status_Status) (*v2.Resource, error) {
 	firstName, lastName := helpers.SplitFullName(user.FullName)
 	profile := map[string]interface{}{
 		"user_id":   user.ID,
@@ -57,7 +69,7 @@ func userResource(user *calendly.User, parentId *v2.ResourceId) (*v2.Resource, e
 		rs.WithUserProfile(profile),
 		rs.WithEmail(user.Email, true),
 		rs.WithUserLogin(user.Email),
-		rs.WithStatus(v2.UserTrait_Status_STATUS_ENABLED),
+		rs.WithStatus(userStatus),
 	}
 
 	created, err := time.Parse(time.RFC3339, user.CreatedAt)
@@ -65,6 +77,11 @@ func userResource(user *calendly.User, parentId *v2.ResourceId) (*v2.Resource, e
 		userOptions = append(userOptions, rs.WithCreatedAt(created))
 	}
 
+	if lastActivity != nil {
+		profile["last_activity"] = lastActivity.Format(time.RFC3339)
+		userOptions = append(userOptions, rs.WithLastLogin(*lastActivity))
+	}
+
 	resource, err := rs.NewUserResource(
 		user.Email,
 		userResourceType,
@@ -104,25 +121,68 @@ func (o *userBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId,
 		bag.Push(pagination.PageState{
 			ResourceTypeID: userResourceType.Id,
 		})
-		bag.Push(pagination.PageState{
-			ResourceTypeID: InvitationsType,
-		})
+		if o.config.includeInvitations() {
+			bag.Push(pagination.PageState{
+				ResourceTypeID: InvitationsType,
+			})
+		}
+		if o.scim != nil {
+			bag.Push(pagination.PageState{
+				ResourceTypeID: DeactivatedUsersType,
+			})
+		}
+
+	case DeactivatedUsersType:
+		snapshot, err := o.snapshots.get(ctx, parentResourceID.Resource)
+		if err != nil {
+			return nil, "", nil, err
+		}
+
+		users, nextPage, err := pageOf(snapshot.deactivatedUsers(), page, o.config.pageSize(nil))
+		if err != nil {
+			return nil, "", nil, err
+		}
+
+		err = bag.Next(nextPage)
+		if err != nil {
+			return nil, "", nil, err
+		}
+
+		for _, u := range users {
+			if !o.config.includeEmail(u.PrimaryEmail()) {
+				continue
+			}
+
+			ur, err := scimUserResource(u, parentResourceID)
+			if err != nil {
+				return nil, "", nil, fmt.Errorf("calendly-connector: failed to create SCIM user resource: %w", err)
+			}
+
+			rv = append(rv, ur)
+		}
 
 	case InvitationsType:
-		pgVars := calendly.NewPaginationVars(ResourcesPageSize, page)
-		invitations, nextPage, rli, err := o.client.ListUserInvitations(ctx, parentResourceID.Resource, pgVars, nil)
+		snapshot, err := o.snapshots.get(ctx, parentResourceID.Resource)
 		if err != nil {
-			return nil, "", nil, fmt.Errorf("calendly-connector: failed to list org invitations: %w", err)
+			return nil, "", nil, err
+		}
+
+		invitations, nextPage, err := pageOf(snapshot.invitations, page, o.config.pageSize(nil))
+		if err != nil {
+			return nil, "", nil, err
 		}
 
-		rldata = append(rldata, rli)
 		err = bag.Next(nextPage)
 		if err != nil {
 			return nil, "", nil, err
 		}
 
 		for _, i := range invitations {
-			ur, err := userInvitationResource(i.Email, parentResourceID)
+			if !o.config.includeInvitation(&i) {
+				continue
+			}
+
+			ur, err := userInvitationResource(i.Email, parentResourceID, snapshot.pendingInvitations[strings.ToLower(i.Email)])
 			if err != nil {
 				return nil, "", nil, fmt.Errorf("calendly-connector: failed to create user invitation resource: %w", err)
 			}
@@ -131,10 +191,14 @@ func (o *userBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId,
 		}
 
 	case userResourceType.Id:
-		pgVars := calendly.NewPaginationVars(ResourcesPageSize, page)
-		users, nextPage, err := o.client.ListUsersUnderOrg(ctx, parentResourceID.Resource, pgVars, nil)
+		snapshot, err := o.snapshots.get(ctx, parentResourceID.Resource)
+		if err != nil {
+			return nil, "", nil, err
+		}
+
+		users, nextPage, err := pageOf(snapshot.memberships, page, o.config.pageSize(nil))
 		if err != nil {
-			return nil, "", nil, fmt.Errorf("calendly-connector: failed to list users: %w", err)
+			return nil, "", nil, err
 		}
 
 		err = bag.Next(nextPage)
@@ -143,7 +207,20 @@ func (o *userBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId,
 		}
 
 		for _, u := range users {
-			ur, err := userResource(u.User, parentResourceID)
+			if !o.config.includeMembership(&u) {
+				continue
+			}
+
+			lastActivity, rla, err := o.lastActivity(ctx, parentResourceID.Resource, u.User.ID)
+			if err != nil {
+				return nil, "", nil, fmt.Errorf("calendly-connector: failed to get user last activity: %w", err)
+			}
+
+			if rla != nil {
+				rldata = append(rldata, rla)
+			}
+
+			ur, err := userResource(u.User, parentResourceID, lastActivity, scimStatus(snapshot.scimUser(u.User.Email)))
 			if err != nil {
 				return nil, "", nil, fmt.Errorf("calendly-connector: failed to create user resource: %w", err)
 			}
@@ -173,9 +250,22 @@ func (o *userBuilder) Grants(ctx context.Context, resource *v2.Resource, pToken
 	return nil, "", nil, nil
 }
 
-func newUserBuilder(client *calendly.Client) *userBuilder {
+// lastActivity returns start time of the most recent meeting hosted by the user within the configured look-back window.
+// Nil is returned when the lookup is disabled or the user hasn't hosted any meeting in the window.
+func (o *userBuilder) lastActivity(ctx context.Context, orgURI, userURI string) (*time.Time, *v2.RateLimitDescription, error) {
+	if o.config == nil || o.config.ActivityLookback <= 0 {
+		return nil, nil, nil
+	}
+
+	return lastHostedEvent(ctx, o.client, orgURI, userURI, o.config.ActivityLookback)
+}
+
+func newUserBuilder(client *calendly.Client, scimClient *scim.Client, config *Config, snapshots *snapshotStore) *userBuilder {
 	return &userBuilder{
 		client:       client,
+		scim:         scimClient,
 		resourceType: userResourceType,
+		config:       config,
+		snapshots:    snapshots,
 	}
 }
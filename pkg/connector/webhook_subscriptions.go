@@ -0,0 +1,211 @@
+package connector
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/conductorone/baton-calendly/pkg/calendly"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	rs "github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// signingKeySize is the number of random bytes of a generated webhook signing key.
+const signingKeySize = 32
+
+type webhookSubscriptionBuilder struct {
+	client       *calendly.Client
+	resourceType *v2.ResourceType
+	config       *Config
+}
+
+func (w *webhookSubscriptionBuilder) ResourceType(ctx context.Context) *v2.ResourceType {
+	return webhookSubscriptionResourceType
+}
+
+func webhookSubscriptionResource(subscription *calendly.WebhookSubscription, parentID *v2.ResourceId) (*v2.Resource, error) {
+	resource, err := rs.NewResource(
+		subscription.CallbackURL,
+		webhookSubscriptionResourceType,
+		subscription.ID,
+		rs.WithParentResourceID(parentID),
+		rs.WithDescription(fmt.Sprintf("%s %s scoped subscription to %s", subscription.State, subscription.Scope, strings.Join(subscription.Events, ", "))),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return resource, nil
+}
+
+// List returns organization scoped webhook subscriptions.
+func (w *webhookSubscriptionBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId, pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	if parentResourceID == nil {
+		return nil, "", nil, nil
+	}
+
+	bag, page, err := parsePageToken(pToken.Token, &v2.ResourceId{ResourceType: webhookSubscriptionResourceType.Id})
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	if err := w.config.waitForRateLimit(ctx, w.client); err != nil {
+		return nil, "", nil, err
+	}
+
+	pgVars := calendly.NewPaginationVars(w.config.pageSize(w.client), page)
+	subscriptions, nextPage, rls, err := w.client.ListWebhookSubscriptions(ctx, parentResourceID.Resource, calendly.WebhookScopeOrganization, pgVars)
+	if err != nil {
+		// webhooks are not available on the free plan
+		if status.Code(err) == codes.PermissionDenied {
+			return nil, "", nil, nil
+		}
+
+		return nil, "", nil, fmt.Errorf("calendly-connector: failed to list webhook subscriptions: %w", err)
+	}
+
+	var rv []*v2.Resource
+	for _, s := range subscriptions {
+		sr, err := webhookSubscriptionResource(&s, parentResourceID)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("calendly-connector: failed to create webhook subscription resource: %w", err)
+		}
+
+		rv = append(rv, sr)
+	}
+
+	next, err := bag.NextToken(nextPage)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	return rv, next, WithRateLimitAnnotations(rls), nil
+}
+
+// Entitlements always returns an empty slice for webhook subscriptions.
+func (w *webhookSubscriptionBuilder) Entitlements(_ context.Context, _ *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	return nil, "", nil, nil
+}
+
+// Grants always returns an empty slice for webhook subscriptions.
+func (w *webhookSubscriptionBuilder) Grants(_ context.Context, _ *v2.Resource, _ *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	return nil, "", nil, nil
+}
+
+// Rotate replaces the signing key of the webhook subscription. Calendly can't change the key of an existing
+// subscription, so the subscription is re-created with the same callback URL, events and scope under a new key.
+// The old subscription is deleted only after the new one is created, its resource ID changes with the rotation.
+// When Calendly refuses a second subscription of the callback URL with a conflict, the rotation is refused
+// and the old subscription is kept, since deleting it first could leave the organization without a webhook.
+// A new key is returned even when the old subscription can't be deleted afterwards, since it is already in use.
+func (w *webhookSubscriptionBuilder) Rotate(
+	ctx context.Context,
+	resourceId *v2.ResourceId,
+	credentialOptions *v2.CredentialOptions,
+) ([]*v2.PlaintextData, annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	if resourceId.ResourceType != webhookSubscriptionResourceType.Id {
+		return nil, nil, status.Errorf(codes.InvalidArgument, "calendly-connector: unsupported resource type %s for rotation", resourceId.ResourceType)
+	}
+
+	old, rlg, err := w.client.GetWebhookSubscription(ctx, resourceId.Resource)
+	if err != nil {
+		return nil, nil, fmt.Errorf("calendly-connector: failed to get webhook subscription: %w", err)
+	}
+
+	signingKey, err := newSigningKey()
+	if err != nil {
+		return nil, nil, fmt.Errorf("calendly-connector: failed to generate signing key: %w", err)
+	}
+
+	body := &calendly.WebhookSubscriptionBody{
+		URL:          old.CallbackURL,
+		Events:       old.Events,
+		Organization: old.Organization,
+		User:         old.User,
+		Group:        old.Group,
+		Scope:        old.Scope,
+		SigningKey:   signingKey,
+	}
+
+	var rldata []*v2.RateLimitDescription
+	rldata = append(rldata, rlg)
+
+	created, rlc, err := w.client.CreateWebhookSubscription(ctx, body)
+	if status.Code(err) == codes.AlreadyExists {
+		return nil, nil, status.Errorf(
+			codes.FailedPrecondition,
+			"calendly-connector: Calendly allows a single subscription of %s, rotating the signing key of %s needs a distinct callback URL",
+			old.CallbackURL,
+			old.ID,
+		)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("calendly-connector: failed to create webhook subscription with new signing key: %w", err)
+	}
+	rldata = append(rldata, rlc)
+
+	l.Info(
+		"calendly-connector: created webhook subscription with rotated signing key",
+		zap.String("old_subscription", old.ID),
+		zap.String("new_subscription", created.ID),
+		zap.String("callback_url", created.CallbackURL),
+	)
+
+	annos := annotations.Annotations{}
+	rld, err := w.client.DeleteWebhookSubscription(ctx, old.ID)
+	if err != nil {
+		// the new subscription is live and signs with the new key, so the key is returned regardless
+		l.Error(
+			"calendly-connector: failed to delete webhook subscription after rotation, delete it manually",
+			zap.String("old_subscription", old.ID),
+			zap.String("new_subscription", created.ID),
+			zap.Error(err),
+		)
+
+		leftover, err := structpb.NewStruct(map[string]interface{}{
+			"undeleted_subscription": old.ID,
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("calendly-connector: failed to create rotation annotation: %w", err)
+		}
+		annos.Append(leftover)
+	} else {
+		rldata = append(rldata, rld)
+	}
+
+	return []*v2.PlaintextData{
+		{
+			Name:        "signing_key",
+			Description: fmt.Sprintf("Signing key of webhook subscription %s", created.ID),
+			Bytes:       []byte(signingKey),
+		},
+	}, mergeAnnotations(annos, WithRateLimitAnnotations(rldata...)), nil
+}
+
+func newSigningKey() (string, error) {
+	b := make([]byte, signingKeySize)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+func newWebhookSubscriptionBuilder(client *calendly.Client, config *Config) *webhookSubscriptionBuilder {
+	return &webhookSubscriptionBuilder{
+		client:       client,
+		resourceType: webhookSubscriptionResourceType,
+		config:       config,
+	}
+}
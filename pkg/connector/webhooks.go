@@ -0,0 +1,269 @@
+package connector
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/conductorone/baton-calendly/pkg/calendly"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	// maxWebhookBodySize limits the size of a single webhook delivery.
+	maxWebhookBodySize = 1 << 20
+
+	defaultEventsPageSize = 100
+)
+
+// webhookEvents lists the webhook events buffered for the event feed.
+var webhookEvents = []string{
+	calendly.WebhookInviteeCreated,
+	calendly.WebhookInviteeCanceled,
+	calendly.WebhookRoutingFormSubmission,
+}
+
+// WebhookHandler returns HTTP handler receiving Calendly webhooks. Deliveries with a valid signature
+// are appended to the webhook events file, from which ListEvents serves them as usage events.
+func (c *Calendly) WebhookHandler(ctx context.Context, signingKey string, tolerance time.Duration) (http.Handler, error) {
+	if c.config == nil || c.config.WebhookEventsFile == "" {
+		return nil, fmt.Errorf("calendly-connector: webhook events file is required to receive webhooks")
+	}
+
+	if signingKey == "" {
+		return nil, fmt.Errorf("calendly-connector: webhook signing key is required to receive webhooks")
+	}
+
+	l := ctxzap.Extract(ctx)
+
+	var mu sync.Mutex
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookBodySize))
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		err = calendly.VerifyWebhookSignature(r.Header.Get(calendly.WebhookSignatureHeader), body, signingKey, tolerance, time.Now())
+		if err != nil {
+			l.Warn("calendly-connector: rejected webhook", zap.String("remote_addr", r.RemoteAddr), zap.Error(err))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		event, err := calendly.ParseWebhookEvent(body)
+		if err != nil {
+			l.Warn("calendly-connector: invalid webhook payload", zap.Error(err))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		if !slices.Contains(webhookEvents, event.Event) {
+			l.Debug("calendly-connector: ignored webhook", zap.String("event", event.Event))
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		mu.Lock()
+		err = appendWebhookEvent(c.config.WebhookEventsFile, body)
+		mu.Unlock()
+		if err != nil {
+			l.Error("calendly-connector: failed to buffer webhook", zap.String("event", event.Event), zap.Error(err))
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		l.Debug("calendly-connector: buffered webhook", zap.String("event", event.Event), zap.String("created_at", event.CreatedAt))
+		w.WriteHeader(http.StatusNoContent)
+	}), nil
+}
+
+// appendWebhookEvent appends the webhook body to the events file as a single JSON line.
+func appendWebhookEvent(path string, body []byte) error {
+	var line bytes.Buffer
+	if err := json.Compact(&line, body); err != nil {
+		return err
+	}
+	line.WriteByte('\n')
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.Write(line.Bytes())
+
+	return err
+}
+
+// ListEvents returns usage events from webhooks buffered in the webhook events file.
+// The cursor is the number of lines of the file already consumed.
+func (c *Calendly) ListEvents(
+	ctx context.Context,
+	earliestEvent *timestamppb.Timestamp,
+	pToken *pagination.StreamToken,
+) ([]*v2.Event, *pagination.StreamState, annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	offset := 0
+	if pToken.Cursor != "" {
+		var err error
+		offset, err = strconv.Atoi(pToken.Cursor)
+		if err != nil || offset < 0 {
+			return nil, nil, nil, fmt.Errorf("calendly-connector: invalid events cursor %s", pToken.Cursor)
+		}
+	}
+
+	state := &pagination.StreamState{Cursor: strconv.Itoa(offset)}
+	if c.config == nil || c.config.WebhookEventsFile == "" {
+		return nil, state, nil, nil
+	}
+
+	f, err := os.Open(c.config.WebhookEventsFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, state, nil, nil
+	}
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("calendly-connector: failed to open webhook events file: %w", err)
+	}
+	defer f.Close()
+
+	size := pToken.Size
+	if size <= 0 {
+		size = defaultEventsPageSize
+	}
+
+	var rv []*v2.Event
+	r := bufio.NewReader(f)
+	line := 0
+	for {
+		data, err := r.ReadBytes('\n')
+		if err != nil {
+			// a partial line is still being written, it is read on the next call
+			break
+		}
+
+		line++
+		if line <= offset {
+			continue
+		}
+
+		if line > offset+size {
+			state.HasMore = true
+			line--
+			break
+		}
+
+		event, err := calendly.ParseWebhookEvent(data)
+		if err != nil {
+			l.Warn("calendly-connector: skipping invalid buffered webhook", zap.Int("line", line), zap.Error(err))
+			continue
+		}
+
+		occurredAt := event.OccurredAt()
+		if earliestEvent != nil && occurredAt.Before(earliestEvent.AsTime()) {
+			continue
+		}
+
+		events, err := usageEvents(event, occurredAt)
+		if err != nil {
+			l.Warn("calendly-connector: skipping invalid buffered webhook", zap.Int("line", line), zap.Error(err))
+			continue
+		}
+
+		rv = append(rv, events...)
+	}
+
+	state.Cursor = strconv.Itoa(max(line, offset))
+
+	return rv, state, nil, nil
+}
+
+// usageEvents converts the webhook event into usage events. Bookings and cancellations produce an event
+// for every host of the event type, routing form submissions an event of the routing form.
+func usageEvents(event *calendly.WebhookEvent, occurredAt time.Time) ([]*v2.Event, error) {
+	switch event.Event {
+	case calendly.WebhookInviteeCreated, calendly.WebhookInviteeCanceled:
+		invitee, err := event.Invitee()
+		if err != nil {
+			return nil, err
+		}
+
+		if invitee.ScheduledEvent == nil || invitee.ScheduledEvent.EventType == "" {
+			return nil, fmt.Errorf("missing scheduled event of invitee %s", invitee.ID)
+		}
+
+		var rv []*v2.Event
+		for _, m := range invitee.ScheduledEvent.Memberships {
+			rv = append(rv, usageEvent(
+				fmt.Sprintf("%s:%s:%s", event.Event, invitee.ID, m.User),
+				occurredAt,
+				resourceRef(eventTypeResourceType, invitee.ScheduledEvent.EventType),
+				resourceRef(userResourceType, m.User),
+			))
+		}
+
+		return rv, nil
+
+	case calendly.WebhookRoutingFormSubmission:
+		submission, err := event.RoutingFormSubmission()
+		if err != nil {
+			return nil, err
+		}
+
+		return []*v2.Event{
+			usageEvent(
+				fmt.Sprintf("%s:%s", event.Event, submission.ID),
+				occurredAt,
+				resourceRef(routingFormResourceType, submission.RoutingForm),
+				nil,
+			),
+		}, nil
+	}
+
+	return nil, nil
+}
+
+func usageEvent(id string, occurredAt time.Time, target, actor *v2.Resource) *v2.Event {
+	return &v2.Event{
+		Id:         id,
+		OccurredAt: timestamppb.New(occurredAt),
+		Event: &v2.Event_UsageEvent{
+			UsageEvent: &v2.UsageEvent{
+				TargetResource: target,
+				ActorResource:  actor,
+			},
+		},
+	}
+}
+
+// resourceRef returns resource carrying only the ID, to reference resources known from the sync.
+func resourceRef(resourceType *v2.ResourceType, id string) *v2.Resource {
+	return &v2.Resource{
+		Id: &v2.ResourceId{
+			ResourceType: resourceType.Id,
+			Resource:     id,
+		},
+	}
+}
@@ -0,0 +1,177 @@
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/conductorone/baton-calendly/pkg/calendly"
+	"github.com/conductorone/baton-sdk/pkg/uhttp"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	UsersEndpoint = "/Users"
+	UserEndpoint  = "/Users/%s"
+)
+
+// Client talks to the SCIM 2.0 API of a Calendly Enterprise organization. It uses its own
+// base URL and token, separate from the Calendly API ones.
+type Client struct {
+	wrapper *uhttp.BaseHttpClient
+	baseURL *url.URL
+}
+
+// NewClient creates SCIM API client for the base URL, which includes the SCIM path prefix.
+func NewClient(ctx context.Context, baseURL, token string) (*Client, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SCIM base URL: %w", err)
+	}
+
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid SCIM base URL: %s", baseURL)
+	}
+
+	httpClient, err := uhttp.NewBearerAuth(token).GetClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// user states change with every grant and revoke, responses must not be cached
+	wrapper, err := uhttp.NewBaseHttpClientWithContext(
+		context.WithValue(ctx, uhttp.ContextKey{}, uhttp.CacheConfig{DisableCache: true}),
+		httpClient,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{
+		wrapper: wrapper,
+		baseURL: u,
+	}, nil
+}
+
+func (c *Client) prepareURL(path string) *url.URL {
+	u := *c.baseURL
+	u.Path = strings.TrimSuffix(u.Path, "/") + path
+	return &u
+}
+
+// ListUsers returns a page of SCIM users starting at the 1-based startIndex and the total number of users.
+func (c *Client) ListUsers(ctx context.Context, startIndex, count int) ([]User, int, error) {
+	u := c.prepareURL(UsersEndpoint)
+	queryParams := &url.Values{}
+	queryParams.Set("startIndex", strconv.Itoa(startIndex))
+	queryParams.Set("count", strconv.Itoa(count))
+
+	var res ListResponse
+	err := c.do(ctx, http.MethodGet, u, nil, &res, queryParams)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return res.Resources, res.TotalResults, nil
+}
+
+// FindUserByEmail returns the SCIM user with the user name, nil if there is none.
+func (c *Client) FindUserByEmail(ctx context.Context, email string) (*User, error) {
+	u := c.prepareURL(UsersEndpoint)
+	queryParams := &url.Values{}
+	queryParams.Set("filter", fmt.Sprintf("userName eq %q", email))
+
+	var res ListResponse
+	err := c.do(ctx, http.MethodGet, u, nil, &res, queryParams)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(res.Resources) == 0 {
+		return nil, nil
+	}
+
+	return &res.Resources[0], nil
+}
+
+// CreateUser provisions the user directly into the organization, without an invitation.
+func (c *Client) CreateUser(ctx context.Context, user *User) (*User, error) {
+	u := c.prepareURL(UsersEndpoint)
+
+	body := *user
+	body.Schemas = []string{UserSchema}
+
+	var res User
+	err := c.do(ctx, http.MethodPost, u, &body, &res, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+// SetActive deactivates or reactivates the user.
+func (c *Client) SetActive(ctx context.Context, userID string, active bool) error {
+	u := c.prepareURL(fmt.Sprintf(UserEndpoint, url.PathEscape(userID)))
+
+	body := &PatchOp{
+		Schemas: []string{PatchOpSchema},
+		Operations: []PatchOperation{
+			{
+				Op:    PatchOpReplace,
+				Path:  "active",
+				Value: active,
+			},
+		},
+	}
+
+	return c.do(ctx, http.MethodPatch, u, body, nil, nil)
+}
+
+func (c *Client) do(ctx context.Context, method string, urlAddress *url.URL, body interface{}, response interface{}, queryParams *url.Values) error {
+	reqOptions := []uhttp.RequestOption{uhttp.WithAccept("application/scim+json")}
+	if body != nil {
+		reqOptions = append(reqOptions, uhttp.WithJSONBody(body))
+	}
+
+	req, err := c.wrapper.NewRequest(ctx, method, urlAddress, reqOptions...)
+	if err != nil {
+		return err
+	}
+
+	if queryParams != nil {
+		req.URL.RawQuery = queryParams.Encode()
+	}
+
+	options := []uhttp.DoOption{WithErrorResponse(&ErrorResponse{})}
+	if response != nil {
+		options = append(options, uhttp.WithJSONResponse(response))
+	}
+
+	resp, err := c.wrapper.Do(req, options...)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	return nil
+}
+
+func WithErrorResponse(resource *ErrorResponse) uhttp.DoOption {
+	return func(resp *uhttp.WrapperResponse) error {
+		if resp.StatusCode < 300 {
+			return nil
+		}
+
+		if err := json.Unmarshal(resp.Body, resource); err != nil || resource.Detail == "" {
+			return status.Error(calendly.ErrorCode(resp.StatusCode), fmt.Sprintf("SCIM request failed with status %d", resp.StatusCode))
+		}
+
+		return status.Error(calendly.ErrorCode(resp.StatusCode), fmt.Sprintf("SCIM request failed with status %d: %s", resp.StatusCode, resource.Detail))
+	}
+}
@@ -0,0 +1,290 @@
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const testToken = "scim-token"
+
+// scimServer is a minimal SCIM 2.0 stand-in keeping users in memory.
+type scimServer struct {
+	mu    sync.Mutex
+	users []User
+}
+
+func (s *scimServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.Header.Get("Authorization") != "Bearer "+testToken {
+		writeSCIM(w, http.StatusUnauthorized, &ErrorResponse{Detail: "invalid token", Status: "401"})
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/scim/v2")
+	switch {
+	case path == UsersEndpoint && r.Method == http.MethodGet:
+		users := s.users
+		if filter := r.URL.Query().Get("filter"); filter != "" {
+			users = nil
+			for _, u := range s.users {
+				if filter == fmt.Sprintf("userName eq %q", u.UserName) {
+					users = append(users, u)
+				}
+			}
+		}
+
+		start, count := len(users), len(users)
+		if v := r.URL.Query().Get("startIndex"); v != "" {
+			start, _ = strconv.Atoi(v)
+			count, _ = strconv.Atoi(r.URL.Query().Get("count"))
+		} else {
+			start = 1
+		}
+
+		page := []User{}
+		for i := start - 1; i >= 0 && i < len(users) && len(page) < count; i++ {
+			page = append(page, users[i])
+		}
+
+		writeSCIM(w, http.StatusOK, &ListResponse{
+			Schemas:      []string{ListSchema},
+			TotalResults: len(users),
+			StartIndex:   start,
+			ItemsPerPage: len(page),
+			Resources:    page,
+		})
+
+	case path == UsersEndpoint && r.Method == http.MethodPost:
+		var u User
+		if err := json.NewDecoder(r.Body).Decode(&u); err != nil {
+			writeSCIM(w, http.StatusBadRequest, &ErrorResponse{Detail: err.Error(), Status: "400"})
+			return
+		}
+
+		for _, existing := range s.users {
+			if existing.UserName == u.UserName {
+				writeSCIM(w, http.StatusConflict, &ErrorResponse{Detail: "user already exists", Status: "409"})
+				return
+			}
+		}
+
+		u.ID = fmt.Sprintf("id-%d", len(s.users)+1)
+		s.users = append(s.users, u)
+		writeSCIM(w, http.StatusCreated, &u)
+
+	case strings.HasPrefix(path, UsersEndpoint+"/") && r.Method == http.MethodPatch:
+		var op PatchOp
+		if err := json.NewDecoder(r.Body).Decode(&op); err != nil || len(op.Operations) != 1 || op.Operations[0].Path != "active" {
+			writeSCIM(w, http.StatusBadRequest, &ErrorResponse{Detail: "unsupported patch", Status: "400"})
+			return
+		}
+
+		id := strings.TrimPrefix(path, UsersEndpoint+"/")
+		for i := range s.users {
+			if s.users[i].ID == id {
+				s.users[i].Active, _ = op.Operations[0].Value.(bool)
+				writeSCIM(w, http.StatusOK, &s.users[i])
+				return
+			}
+		}
+
+		writeSCIM(w, http.StatusNotFound, &ErrorResponse{Detail: "user not found", Status: "404"})
+
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func writeSCIM(w http.ResponseWriter, statusCode int, body interface{}) {
+	w.Header().Set("Content-Type", "application/scim+json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func newTestClient(t *testing.T, token string, users ...User) (*Client, *scimServer) {
+	t.Helper()
+
+	s := &scimServer{users: users}
+	server := httptest.NewServer(s)
+	t.Cleanup(server.Close)
+
+	c, err := NewClient(context.Background(), server.URL+"/scim/v2/", token)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	return c, s
+}
+
+func TestNewClientInvalidBaseURL(t *testing.T) {
+	for _, baseURL := range []string{"", "scim/v2", "https://", "://example.com"} {
+		if _, err := NewClient(context.Background(), baseURL, testToken); err == nil {
+			t.Errorf("NewClient(%q) succeeded, want error", baseURL)
+		}
+	}
+}
+
+func TestListUsers(t *testing.T) {
+	users := []User{
+		{ID: "1", UserName: "a@example.com", Active: true},
+		{ID: "2", UserName: "b@example.com", Active: false},
+		{ID: "3", UserName: "c@example.com", Active: true},
+	}
+
+	tests := []struct {
+		name       string
+		startIndex int
+		count      int
+		want       []string
+	}{
+		{name: "first page", startIndex: 1, count: 2, want: []string{"1", "2"}},
+		{name: "last page", startIndex: 3, count: 2, want: []string{"3"}},
+		{name: "past the end", startIndex: 4, count: 2, want: nil},
+	}
+
+	c, _ := newTestClient(t, testToken, users...)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, total, err := c.ListUsers(context.Background(), tt.startIndex, tt.count)
+			if err != nil {
+				t.Fatalf("ListUsers: %v", err)
+			}
+
+			if total != len(users) {
+				t.Errorf("total = %d, want %d", total, len(users))
+			}
+
+			var ids []string
+			for _, u := range got {
+				ids = append(ids, u.ID)
+			}
+
+			if strings.Join(ids, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("ids = %v, want %v", ids, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindUserByEmail(t *testing.T) {
+	c, _ := newTestClient(t, testToken, User{ID: "1", UserName: "a@example.com", Active: true})
+
+	tests := []struct {
+		email  string
+		wantID string
+	}{
+		{email: "a@example.com", wantID: "1"},
+		{email: "missing@example.com", wantID: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.email, func(t *testing.T) {
+			got, err := c.FindUserByEmail(context.Background(), tt.email)
+			if err != nil {
+				t.Fatalf("FindUserByEmail: %v", err)
+			}
+
+			if tt.wantID == "" {
+				if got != nil {
+					t.Errorf("got user %q, want none", got.ID)
+				}
+				return
+			}
+
+			if got == nil || got.ID != tt.wantID {
+				t.Errorf("got %+v, want user %q", got, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestCreateUserAndSetActive(t *testing.T) {
+	ctx := context.Background()
+	c, s := newTestClient(t, testToken)
+
+	created, err := c.CreateUser(ctx, &User{UserName: "new@example.com", Active: true})
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+
+	if created.ID == "" {
+		t.Fatal("created user has no ID")
+	}
+
+	if len(s.users) != 1 || len(s.users[0].Schemas) != 1 || s.users[0].Schemas[0] != UserSchema {
+		t.Errorf("server users = %+v, want one user with the user schema", s.users)
+	}
+
+	// lookups must see every state change, responses are not cached
+	for _, active := range []bool{false, true, false} {
+		if err := c.SetActive(ctx, created.ID, active); err != nil {
+			t.Fatalf("SetActive(%v): %v", active, err)
+		}
+
+		got, err := c.FindUserByEmail(ctx, "new@example.com")
+		if err != nil {
+			t.Fatalf("FindUserByEmail: %v", err)
+		}
+
+		if got == nil || got.Active != active {
+			t.Errorf("after SetActive(%v) got %+v", active, got)
+		}
+	}
+}
+
+func TestErrorCodes(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		call func(c *Client) error
+		want codes.Code
+	}{
+		{
+			name: "duplicate user",
+			call: func(c *Client) error {
+				_, err := c.CreateUser(ctx, &User{UserName: "a@example.com"})
+				return err
+			},
+			want: codes.AlreadyExists,
+		},
+		{
+			name: "missing user",
+			call: func(c *Client) error {
+				return c.SetActive(ctx, "missing", false)
+			},
+			want: codes.NotFound,
+		},
+	}
+
+	c, _ := newTestClient(t, testToken, User{ID: "1", UserName: "a@example.com", Active: true})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call(c)
+			if status.Code(err) != tt.want {
+				t.Errorf("error = %v, want code %s", err, tt.want)
+			}
+		})
+	}
+
+	t.Run("invalid token", func(t *testing.T) {
+		c, _ := newTestClient(t, "wrong")
+
+		_, _, err := c.ListUsers(ctx, 1, 10)
+		if status.Code(err) != codes.Unauthenticated {
+			t.Errorf("error = %v, want code %s", err, codes.Unauthenticated)
+		}
+	})
+}
@@ -0,0 +1,64 @@
+package scim
+
+const (
+	UserSchema     = "urn:ietf:params:scim:schemas:core:2.0:User"
+	ListSchema     = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	PatchOpSchema  = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	PatchOpReplace = "replace"
+)
+
+type User struct {
+	Schemas  []string `json:"schemas,omitempty"`
+	ID       string   `json:"id,omitempty"`
+	UserName string   `json:"userName"`
+	Name     *Name    `json:"name,omitempty"`
+	Emails   []Email  `json:"emails,omitempty"`
+	Active   bool     `json:"active"`
+}
+
+type Name struct {
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+}
+
+type Email struct {
+	Value   string `json:"value"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+// PrimaryEmail returns the primary email of the user, falling back to the user name.
+func (u *User) PrimaryEmail() string {
+	for _, e := range u.Emails {
+		if e.Primary {
+			return e.Value
+		}
+	}
+
+	return u.UserName
+}
+
+type ListResponse struct {
+	Schemas      []string `json:"schemas"`
+	TotalResults int      `json:"totalResults"`
+	StartIndex   int      `json:"startIndex"`
+	ItemsPerPage int      `json:"itemsPerPage"`
+	Resources    []User   `json:"Resources"`
+}
+
+type PatchOp struct {
+	Schemas    []string         `json:"schemas"`
+	Operations []PatchOperation `json:"Operations"`
+}
+
+type PatchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path,omitempty"`
+	Value interface{} `json:"value"`
+}
+
+type ErrorResponse struct {
+	Schemas []string `json:"schemas"`
+	Detail  string   `json:"detail"`
+	Status  string   `json:"status"`
+}